@@ -0,0 +1 @@
+terraform import kwgithub_organization_ruleset_allowed_merge_methods.example my-org:12345
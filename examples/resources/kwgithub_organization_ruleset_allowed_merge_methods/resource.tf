@@ -0,0 +1,5 @@
+resource "kwgithub_organization_ruleset_allowed_merge_methods" "example" {
+  organization          = "my-org"
+  ruleset_id            = "12345"
+  allowed_merge_methods = ["squash"]
+}
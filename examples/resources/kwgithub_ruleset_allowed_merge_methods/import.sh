@@ -0,0 +1,8 @@
+# Import by ruleset ID
+terraform import kwgithub_ruleset_allowed_merge_methods.example owner/repo:12345
+
+# Import by ruleset name
+terraform import kwgithub_ruleset_allowed_merge_methods.example owner/repo:main-protection
+
+# Without an owner, the provider's owner is used
+terraform import kwgithub_ruleset_allowed_merge_methods.example repo:12345
@@ -0,0 +1,5 @@
+# [owner/]repo:ruleset_id:actor_type:actor_id
+terraform import kwgithub_ruleset_bypass_actor.deploy_app my-org/my-repo:12345:Integration:123456
+
+# Actor types without an ID are imported with [owner/]repo:ruleset_id:actor_type
+terraform import kwgithub_ruleset_bypass_actor.org_admins my-org/my-repo:12345:OrganizationAdmin
@@ -0,0 +1,14 @@
+resource "kwgithub_ruleset_bypass_actor" "deploy_app" {
+  repository  = "my-repo"
+  ruleset_id  = "12345"
+  actor_type  = "Integration"
+  actor_id    = 123456
+  bypass_mode = "always"
+}
+
+resource "kwgithub_ruleset_bypass_actor" "org_admins" {
+  repository  = "my-repo"
+  ruleset_id  = "12345"
+  actor_type  = "OrganizationAdmin"
+  bypass_mode = "pull_request"
+}
@@ -0,0 +1 @@
+terraform import kwgithub_ruleset_merge_queue.example my-org/my-repo:12345
@@ -0,0 +1,11 @@
+resource "kwgithub_ruleset_merge_queue" "example" {
+  repository                        = "my-repo"
+  ruleset_id                        = "12345"
+  check_response_timeout_minutes    = 30
+  grouping_strategy                 = "HEADGREEN"
+  max_entries_to_build              = 10
+  max_entries_to_merge              = 5
+  merge_method                      = "SQUASH"
+  min_entries_to_merge              = 1
+  min_entries_to_merge_wait_minutes = 5
+}
@@ -0,0 +1,2 @@
+# [owner/]repo:ruleset_id:type
+terraform import kwgithub_ruleset_pattern_rule.commit_message my-org/my-repo:12345:commit_message_pattern
@@ -0,0 +1,16 @@
+resource "kwgithub_ruleset_pattern_rule" "commit_message" {
+  repository = "my-repo"
+  ruleset_id = "12345"
+  type       = "commit_message_pattern"
+  name       = "Ticket reference"
+  operator   = "regex"
+  pattern    = "^[A-Z]+-[0-9]+ "
+}
+
+resource "kwgithub_ruleset_pattern_rule" "author_email" {
+  repository = "my-repo"
+  ruleset_id = "12345"
+  type       = "commit_author_email_pattern"
+  operator   = "ends_with"
+  pattern    = "@example.com"
+}
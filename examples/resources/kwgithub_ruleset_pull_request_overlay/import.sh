@@ -0,0 +1 @@
+terraform import kwgithub_ruleset_pull_request_overlay.example my-org/my-repo:12345
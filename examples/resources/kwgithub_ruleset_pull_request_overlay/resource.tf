@@ -0,0 +1,15 @@
+resource "kwgithub_ruleset_pull_request_overlay" "example" {
+  repository                            = "my-repo"
+  ruleset_id                            = "12345"
+  required_approving_review_count       = 1
+  automatic_copilot_code_review_enabled = true
+
+  required_reviewers = [
+    {
+      minimum_approvals = 1
+      file_patterns     = ["terraform/**"]
+      reviewer_id       = 1234567
+      reviewer_type     = "Team"
+    },
+  ]
+}
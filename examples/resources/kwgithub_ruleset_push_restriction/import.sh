@@ -0,0 +1,6 @@
+# Restricted paths and extensions: [owner/]repo:ruleset_id:rule_type:value
+terraform import kwgithub_ruleset_push_restriction.secrets 'my-org/my-repo:12345:file_path_restriction:secrets/**'
+terraform import kwgithub_ruleset_push_restriction.jar 'my-org/my-repo:12345:file_extension_restriction:*.jar'
+
+# Limits: [owner/]repo:ruleset_id:rule_type
+terraform import kwgithub_ruleset_push_restriction.max_file_size my-org/my-repo:12345:max_file_size
@@ -0,0 +1,17 @@
+resource "kwgithub_ruleset_push_restriction" "secrets" {
+  repository = "my-repo"
+  ruleset_id = "12345"
+  file_path  = "secrets/**"
+}
+
+resource "kwgithub_ruleset_push_restriction" "jar" {
+  repository     = "my-repo"
+  ruleset_id     = "12345"
+  file_extension = "*.jar"
+}
+
+resource "kwgithub_ruleset_push_restriction" "max_file_size" {
+  repository    = "my-repo"
+  ruleset_id    = "12345"
+  max_file_size = 50
+}
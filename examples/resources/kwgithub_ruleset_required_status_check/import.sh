@@ -0,0 +1,2 @@
+# [owner/]repo:ruleset_id:context
+terraform import kwgithub_ruleset_required_status_check.build my-org/my-repo:12345:ci/build
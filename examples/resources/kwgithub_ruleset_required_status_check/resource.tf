@@ -0,0 +1,6 @@
+resource "kwgithub_ruleset_required_status_check" "build" {
+  repository     = "my-repo"
+  ruleset_id     = "12345"
+  context        = "ci/build"
+  integration_id = 15368
+}
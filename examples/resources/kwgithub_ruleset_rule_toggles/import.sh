@@ -0,0 +1,2 @@
+# [owner/]repo:ruleset_id
+terraform import kwgithub_ruleset_rule_toggles.example my-org/my-repo:12345
@@ -0,0 +1,7 @@
+resource "kwgithub_ruleset_rule_toggles" "example" {
+  repository              = "my-repo"
+  ruleset_id              = "12345"
+  required_linear_history = true
+  non_fast_forward        = true
+  deletion                = false
+}
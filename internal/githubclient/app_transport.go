@@ -0,0 +1,130 @@
+package githubclient
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// tokenRefreshMargin is how long before expiry an installation token is
+// considered stale and proactively refreshed.
+const tokenRefreshMargin = 5 * time.Minute
+
+// appTransport authenticates requests with a GitHub App installation token.
+// The token is refreshed shortly before it expires and once more when GitHub
+// rejects it with a 401, so long-running applies survive the one hour token
+// lifetime without rebuilding the Client.
+type appTransport struct {
+	base           http.RoundTripper
+	baseURL        string
+	appID          string
+	installationID string
+	pemData        []byte
+	now            func() time.Time
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+func newAppTransport(base http.RoundTripper, baseURL, appID, installationID, pemData string) *appTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &appTransport{
+		base:           base,
+		baseURL:        baseURL,
+		appID:          appID,
+		installationID: installationID,
+		pemData:        []byte(pemData),
+		now:            time.Now,
+	}
+}
+
+// Token returns a valid installation token, requesting a new one when the
+// cached token is missing or about to expire.
+func (t *appTransport) Token() (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.token != "" && t.now().Add(tokenRefreshMargin).Before(t.expiresAt) {
+		return t.token, nil
+	}
+	return t.refreshLocked()
+}
+
+// refresh replaces the cached token unless another request already did so
+// after stale was handed out.
+func (t *appTransport) refresh(stale string) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.token != "" && t.token != stale {
+		return t.token, nil
+	}
+	return t.refreshLocked()
+}
+
+func (t *appTransport) refreshLocked() (string, error) {
+	appJWT, err := generateAppJWT(t.appID, t.now(), t.pemData)
+	if err != nil {
+		return "", err
+	}
+
+	token, expiresAt, err := getInstallationAccessToken(t.baseURL, appJWT, t.installationID)
+	if err != nil {
+		return "", err
+	}
+
+	if expiresAt.IsZero() {
+		// Installation tokens are documented to live for one hour.
+		expiresAt = t.now().Add(time.Hour)
+	}
+
+	t.token = token
+	t.expiresAt = expiresAt
+	return token, nil
+}
+
+func (t *appTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.Token()
+	if err != nil {
+		return nil, fmt.Errorf("failed to obtain GitHub App installation token: %w", err)
+	}
+
+	resp, err := t.base.RoundTrip(withBearerToken(req, token))
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	// The body has already been consumed and cannot be replayed.
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return resp, nil
+	}
+
+	// The token was revoked or expired earlier than advertised; refresh it
+	// and retry the request once.
+	token, err = t.refresh(token)
+	if err != nil {
+		return resp, nil
+	}
+
+	retry := withBearerToken(req, token)
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return resp, nil
+		}
+		retry.Body = body
+	}
+	resp.Body.Close()
+
+	return t.base.RoundTrip(retry)
+}
+
+func withBearerToken(req *http.Request, token string) *http.Request {
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+token)
+	return req
+}
@@ -0,0 +1,106 @@
+package githubclient
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testPrivateKeyPEM(t *testing.T) string {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(key),
+	}))
+}
+
+// newTokenServer serves installation tokens named token-1, token-2, ... that
+// expire after ttl, and answers every other path with the bearer token it saw.
+func newTokenServer(t *testing.T, ttl time.Duration, issued *int32) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/app/installations/42/access_tokens", func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(issued, 1)
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"token": "token-%d", "expires_at": %q}`, n, time.Now().Add(ttl).Format(time.RFC3339))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestAppTransportRefreshesBeforeExpiry(t *testing.T) {
+	var issued int32
+	server := newTokenServer(t, time.Hour, &issued)
+
+	transport := newAppTransport(nil, server.URL, "1", "42", testPrivateKeyPEM(t))
+
+	token, err := transport.Token()
+	if err != nil {
+		t.Fatalf("Token failed: %v", err)
+	}
+	if token != "token-1" {
+		t.Errorf("Expected token-1, got %q", token)
+	}
+
+	// Still well within the lifetime: the cached token is reused.
+	token, _ = transport.Token()
+	if token != "token-1" || atomic.LoadInt32(&issued) != 1 {
+		t.Errorf("Expected cached token-1, got %q after %d issues", token, issued)
+	}
+
+	// Move the clock inside the refresh margin.
+	transport.now = func() time.Time { return time.Now().Add(time.Hour - time.Minute) }
+	token, err = transport.Token()
+	if err != nil {
+		t.Fatalf("Token failed: %v", err)
+	}
+	if token != "token-2" {
+		t.Errorf("Expected refreshed token-2, got %q", token)
+	}
+}
+
+func TestAppTransportRetriesOnUnauthorized(t *testing.T) {
+	var issued int32
+	tokenServer := newTokenServer(t, time.Hour, &issued)
+
+	var calls int32
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		if r.Header.Get("Authorization") != "Bearer token-2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer api.Close()
+
+	transport := newAppTransport(nil, tokenServer.URL, "1", "42", testPrivateKeyPEM(t))
+	client := &http.Client{Transport: transport}
+
+	resp, err := client.Get(api.URL)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected 200 after token refresh, got %d", resp.StatusCode)
+	}
+	if calls != 2 {
+		t.Errorf("Expected exactly one retry, got %d calls", calls)
+	}
+}
@@ -5,7 +5,6 @@ import (
 	"crypto/x509"
 	"encoding/json"
 	"encoding/pem"
-	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -19,27 +18,96 @@ import (
 type Client struct {
 	*github.Client
 	Owner string
+
+	rulesets     rulesetCache
+	rulesetLocks keyedMutex
+}
+
+// Option configures optional behavior of a Client.
+type Option func(*options)
+
+type options struct {
+	maxRetries int
+	retryDelay time.Duration
+	writeDelay time.Duration
+}
+
+func newOptions(opts []Option) options {
+	o := options{
+		maxRetries: DefaultMaxRetries,
+		retryDelay: DefaultRetryDelay,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithRetry sets how many times idempotent requests are retried after a rate
+// limit or server error, and the base delay of the backoff between attempts.
+func WithRetry(maxRetries int, retryDelay time.Duration) Option {
+	return func(o *options) {
+		o.maxRetries = maxRetries
+		o.retryDelay = retryDelay
+	}
+}
+
+// WithWriteDelay serializes mutating requests and waits at least delay
+// between them, which helps to stay below GitHub's secondary rate limits.
+func WithWriteDelay(delay time.Duration) Option {
+	return func(o *options) {
+		o.writeDelay = delay
+	}
+}
+
+func (o options) transport() http.RoundTripper {
+	t := newRateLimitTransport(nil, o.maxRetries, o.retryDelay)
+	t.writeDelay = o.writeDelay
+	return t
 }
 
-func NewClient(token, baseURL, owner string) *Client {
-	var tc *http.Client
+func NewClient(token, baseURL, owner string, opts ...Option) *Client {
+	o := newOptions(opts)
+
+	tc := &http.Client{Transport: o.transport()}
 	if token != "" {
-		tc = github.NewClient(nil).WithAuthToken(token).Client()
+		tc = github.NewClient(tc).WithAuthToken(token).Client()
 	}
+	tc.Transport = newETagTransport(tc.Transport)
 
 	client, _ := github.NewClient(tc).WithEnterpriseURLs(baseURL, baseURL)
-	return &Client{client, owner}
+	return &Client{Client: client, Owner: owner}
 }
 
-func NewClientWithApp(appID, installationID, pemFile, baseURL, owner string) *Client {
-	token, err := GenerateOAuthTokenFromApp(baseURL, appID, installationID, pemFile)
-	if err != nil {
-		return nil
+// NewClientWithApp creates a client authenticated as a GitHub App installation.
+// When installationID is empty, the installation is looked up from owner.
+func NewClientWithApp(appID, installationID, pemFile, baseURL, owner string, opts ...Option) (*Client, error) {
+	o := newOptions(opts)
+
+	if installationID == "" {
+		appJWT, err := generateAppJWT(appID, time.Now(), []byte(pemFile))
+		if err != nil {
+			return nil, err
+		}
+		installationID, err = findInstallationID(baseURL, appJWT, owner)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	tc := github.NewClient(nil).WithAuthToken(token).Client()
-	client, _ := github.NewClient(tc).WithEnterpriseURLs(baseURL, baseURL)
-	return &Client{client, owner}
+	transport := newAppTransport(o.transport(), baseURL, appID, installationID, pemFile)
+
+	// Fetch the first token eagerly so misconfiguration surfaces during Configure.
+	if _, err := transport.Token(); err != nil {
+		return nil, err
+	}
+
+	tc := &http.Client{Transport: newETagTransport(transport)}
+	client, err := github.NewClient(tc).WithEnterpriseURLs(baseURL, baseURL)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{Client: client, Owner: owner}, nil
 }
 
 func GenerateOAuthTokenFromApp(baseURL, appID, appInstallationID, pemData string) (string, error) {
@@ -48,7 +116,7 @@ func GenerateOAuthTokenFromApp(baseURL, appID, appInstallationID, pemData string
 		return "", err
 	}
 
-	token, err := getInstallationAccessToken(baseURL, appJWT, appInstallationID)
+	token, _, err := getInstallationAccessToken(baseURL, appJWT, appInstallationID)
 	if err != nil {
 		return "", err
 	}
@@ -59,25 +127,25 @@ func GenerateOAuthTokenFromApp(baseURL, appID, appInstallationID, pemData string
 func generateAppJWT(appID string, issuedAt time.Time, privateKeyPEM []byte) (string, error) {
 	block, _ := pem.Decode(privateKeyPEM)
 	if block == nil {
-		return "", errors.New("failed to parse PEM block containing the key")
+		return "", ErrInvalidPEM
 	}
 
 	privateKey, err := x509.ParsePKCS1PrivateKey(block.Bytes)
 	if err != nil {
 		parsedKey, err := x509.ParsePKCS8PrivateKey(block.Bytes)
 		if err != nil {
-			return "", fmt.Errorf("failed to parse private key: %v", err)
+			return "", fmt.Errorf("%w: %v", ErrInvalidPrivateKey, err)
 		}
 		var ok bool
 		privateKey, ok = parsedKey.(*rsa.PrivateKey)
 		if !ok {
-			return "", errors.New("private key is not RSA")
+			return "", ErrNotRSAKey
 		}
 	}
 
 	appIDInt, err := strconv.Atoi(appID)
 	if err != nil {
-		return "", fmt.Errorf("invalid app ID: %v", err)
+		return "", fmt.Errorf("%w: %v", ErrInvalidAppID, err)
 	}
 
 	claims := jwt.RegisteredClaims{
@@ -95,12 +163,64 @@ func generateAppJWT(appID string, issuedAt time.Time, privateKeyPEM []byte) (str
 	return tokenString, nil
 }
 
-func getInstallationAccessToken(baseURL, appJWT, installationID string) (string, error) {
+// findInstallationID resolves the installation of the app on owner, trying
+// the organization endpoint first and falling back to the user endpoint.
+func findInstallationID(baseURL, appJWT, owner string) (string, error) {
+	var lastErr error
+	for _, kind := range []string{"orgs", "users"} {
+		url := fmt.Sprintf("%s/%s/%s/installation", baseURL, kind, owner)
+
+		req, err := http.NewRequest("GET", url, nil)
+		if err != nil {
+			return "", fmt.Errorf("failed to create request: %v", err)
+		}
+
+		req.Header.Set("Authorization", "Bearer "+appJWT)
+		req.Header.Set("Accept", "application/vnd.github.v3+json")
+		req.Header.Set("User-Agent", "terraform-provider-kw-github")
+
+		client := &http.Client{Timeout: 30 * time.Second}
+		resp, err := client.Do(req)
+		if err != nil {
+			return "", fmt.Errorf("failed to make request: %w", err)
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			lastErr = &AppAuthError{
+				StatusCode: resp.StatusCode,
+				Status:     resp.Status,
+				Body:       string(body),
+				Owner:      owner,
+			}
+			if resp.StatusCode == http.StatusNotFound {
+				continue
+			}
+			return "", lastErr
+		}
+
+		var installation struct {
+			ID int64 `json:"id"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&installation)
+		resp.Body.Close()
+		if err != nil {
+			return "", fmt.Errorf("failed to decode response: %v", err)
+		}
+
+		return strconv.FormatInt(installation.ID, 10), nil
+	}
+
+	return "", lastErr
+}
+
+func getInstallationAccessToken(baseURL, appJWT, installationID string) (string, time.Time, error) {
 	url := fmt.Sprintf("%s/app/installations/%s/access_tokens", baseURL, installationID)
 
 	req, err := http.NewRequest("POST", url, nil)
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: %v", err)
+		return "", time.Time{}, fmt.Errorf("failed to create request: %v", err)
 	}
 
 	req.Header.Set("Authorization", "Bearer "+appJWT)
@@ -110,22 +230,27 @@ func getInstallationAccessToken(baseURL, appJWT, installationID string) (string,
 	client := &http.Client{Timeout: 30 * time.Second}
 	resp, err := client.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to make request: %v", err)
+		return "", time.Time{}, fmt.Errorf("failed to make request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusCreated {
 		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("failed to get access token: %s - %s", resp.Status, string(body))
+		return "", time.Time{}, &AppAuthError{
+			StatusCode: resp.StatusCode,
+			Status:     resp.Status,
+			Body:       string(body),
+		}
 	}
 
 	var tokenResp struct {
-		Token string `json:"token"`
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
 	}
 
 	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
-		return "", fmt.Errorf("failed to decode response: %v", err)
+		return "", time.Time{}, fmt.Errorf("failed to decode response: %v", err)
 	}
 
-	return tokenResp.Token, nil
+	return tokenResp.Token, tokenResp.ExpiresAt, nil
 }
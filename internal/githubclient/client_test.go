@@ -0,0 +1,111 @@
+package githubclient
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGenerateAppJWTErrors(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	ecDER, err := x509.MarshalPKCS8PrivateKey(ecKey)
+	if err != nil {
+		t.Fatalf("failed to marshal key: %v", err)
+	}
+	ecPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: ecDER})
+
+	tests := []struct {
+		name  string
+		appID string
+		pem   []byte
+		want  error
+	}{
+		{"not PEM", "1", []byte("not a key"), ErrInvalidPEM},
+		{"garbage key", "1", pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte("garbage")}), ErrInvalidPrivateKey},
+		{"non-RSA key", "1", ecPEM, ErrNotRSAKey},
+		{"invalid app ID", "Iv1.abc", []byte(testPrivateKeyPEM(t)), ErrInvalidAppID},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := generateAppJWT(test.appID, time.Now(), test.pem)
+			if !errors.Is(err, test.want) {
+				t.Errorf("Expected %v, got %v", test.want, err)
+			}
+		})
+	}
+}
+
+func TestNewClientWithAppInstallationNotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"message":"Not Found"}`))
+	}))
+	defer server.Close()
+
+	_, err := NewClientWithApp("1", "42", testPrivateKeyPEM(t), server.URL, "owner")
+
+	var authErr *AppAuthError
+	if !errors.As(err, &authErr) {
+		t.Fatalf("Expected *AppAuthError, got %T: %v", err, err)
+	}
+	if authErr.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", authErr.StatusCode)
+	}
+}
+
+func TestNewClientWithAppInstallationLookupFails(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"message":"Not Found"}`))
+	}))
+	defer server.Close()
+
+	_, err := NewClientWithApp("1", "", testPrivateKeyPEM(t), server.URL, "someone")
+
+	var authErr *AppAuthError
+	if !errors.As(err, &authErr) {
+		t.Fatalf("Expected *AppAuthError, got %T: %v", err, err)
+	}
+	if authErr.Owner != "someone" {
+		t.Errorf("Expected the lookup of someone to fail, got owner %q", authErr.Owner)
+	}
+	if !strings.Contains(err.Error(), "failed to look up the app installation of someone") {
+		t.Errorf("Expected the error to name the installation lookup, got %q", err)
+	}
+}
+
+func TestNewClientWithAppDiscoversInstallation(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/orgs/someone/installation", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	mux.HandleFunc("/users/someone/installation", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id": 42}`))
+	})
+	mux.HandleFunc("/app/installations/42/access_tokens", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"token": "token-1"}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client, err := NewClientWithApp("1", "", testPrivateKeyPEM(t), server.URL, "someone")
+	if err != nil {
+		t.Fatalf("NewClientWithApp failed: %v", err)
+	}
+	if client == nil {
+		t.Fatal("Expected a client")
+	}
+}
@@ -0,0 +1,82 @@
+package githubclient
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+var (
+	// ErrInvalidPEM is returned when the GitHub App private key does not
+	// contain a PEM block.
+	ErrInvalidPEM = errors.New("failed to parse PEM block containing the key")
+
+	// ErrInvalidPrivateKey is returned when the PEM block is not a PKCS#1 or
+	// PKCS#8 private key.
+	ErrInvalidPrivateKey = errors.New("failed to parse private key")
+
+	// ErrNotRSAKey is returned when the private key is not an RSA key.
+	ErrNotRSAKey = errors.New("private key is not RSA")
+
+	// ErrInvalidAppID is returned when the GitHub App ID is not numeric.
+	ErrInvalidAppID = errors.New("invalid app ID")
+)
+
+// AppAuthError is returned when GitHub rejects a GitHub App authentication
+// request, e.g. the installation access token exchange.
+type AppAuthError struct {
+	StatusCode int
+	Status     string
+	Body       string
+
+	// Owner is set when the request looked up the installation of the app on
+	// Owner, rather than exchanging the app JWT for an access token.
+	Owner string
+}
+
+func (e *AppAuthError) Error() string {
+	if e.Owner != "" {
+		return fmt.Sprintf("failed to look up the app installation of %s: %s - %s", e.Owner, e.Status, e.Body)
+	}
+	return fmt.Sprintf("failed to get access token: %s - %s", e.Status, e.Body)
+}
+
+// IsClockSkew reports whether GitHub rejected the app JWT because of its
+// iat or exp claims, which usually means the local clock is off.
+func (e *AppAuthError) IsClockSkew() bool {
+	if e.StatusCode != http.StatusUnauthorized {
+		return false
+	}
+	body := strings.ToLower(e.Body)
+	return strings.Contains(body, "'issued at' claim") ||
+		strings.Contains(body, "'expiration time' claim")
+}
+
+// RulesetConflictError is returned when a ruleset kept being changed by
+// someone else while it was being modified.
+type RulesetConflictError struct {
+	Key      RulesetKey
+	Attempts int
+}
+
+func (e *RulesetConflictError) Error() string {
+	return fmt.Sprintf("ruleset %s was modified concurrently, giving up after %d attempts", e.Key, e.Attempts)
+}
+
+// RulesetNameError is returned when a ruleset name does not identify exactly
+// one ruleset of a repository.
+type RulesetNameError struct {
+	Owner   string
+	Repo    string
+	Name    string
+	Matches []int64
+}
+
+func (e *RulesetNameError) Error() string {
+	if len(e.Matches) == 0 {
+		return fmt.Sprintf("no ruleset named %q found in %s/%s", e.Name, e.Owner, e.Repo)
+	}
+	return fmt.Sprintf("%d rulesets named %q found in %s/%s (IDs %v)",
+		len(e.Matches), e.Name, e.Owner, e.Repo, e.Matches)
+}
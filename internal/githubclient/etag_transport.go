@@ -0,0 +1,127 @@
+package githubclient
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// etagTransport caches GET responses in memory together with their ETag and
+// revalidates them with If-None-Match. GitHub does not charge 304 Not
+// Modified responses against the rate limit, so repeated reads of unchanged
+// resources are free. Any write to a path drops the cached responses for it.
+type etagTransport struct {
+	base http.RoundTripper
+
+	mu      sync.Mutex
+	entries map[string]*etagEntry
+}
+
+type etagEntry struct {
+	path   string
+	etag   string
+	header http.Header
+	body   []byte
+}
+
+func newETagTransport(base http.RoundTripper) *etagTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &etagTransport{
+		base:    base,
+		entries: make(map[string]*etagEntry),
+	}
+}
+
+func (t *etagTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet {
+		t.invalidate(req.URL.Path)
+		return t.base.RoundTrip(req)
+	}
+
+	key := etagCacheKey(req)
+
+	t.mu.Lock()
+	entry := t.entries[key]
+	t.mu.Unlock()
+
+	if entry != nil && req.Header.Get("If-None-Match") == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("If-None-Match", entry.etag)
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && entry != nil:
+		resp.Body.Close()
+		return entry.response(req, resp), nil
+	case resp.StatusCode == http.StatusOK && resp.Header.Get("ETag") != "":
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+
+		t.mu.Lock()
+		t.entries[key] = &etagEntry{
+			path:   req.URL.Path,
+			etag:   resp.Header.Get("ETag"),
+			header: resp.Header.Clone(),
+			body:   body,
+		}
+		t.mu.Unlock()
+	default:
+		t.mu.Lock()
+		delete(t.entries, key)
+		t.mu.Unlock()
+	}
+
+	return resp, nil
+}
+
+// invalidate drops every cached response for path, regardless of query.
+func (t *etagTransport) invalidate(path string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for key, entry := range t.entries {
+		if entry.path == path {
+			delete(t.entries, key)
+		}
+	}
+}
+
+// response rebuilds a 200 OK response from the cache, keeping the rate limit
+// headers of the 304 response so go-github tracks the current quota.
+func (e *etagEntry) response(req *http.Request, notModified *http.Response) *http.Response {
+	header := e.header.Clone()
+	for name, values := range notModified.Header {
+		if strings.HasPrefix(name, "X-Ratelimit-") {
+			header[name] = values
+		}
+	}
+
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         notModified.Proto,
+		ProtoMajor:    notModified.ProtoMajor,
+		ProtoMinor:    notModified.ProtoMinor,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(e.body)),
+		ContentLength: int64(len(e.body)),
+		Request:       req,
+	}
+}
+
+func etagCacheKey(req *http.Request) string {
+	return req.Header.Get("Accept") + " " + req.URL.String()
+}
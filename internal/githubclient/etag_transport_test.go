@@ -0,0 +1,63 @@
+package githubclient
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestETagTransport(t *testing.T) {
+	var gets, notModified int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		gets++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified++
+			w.Header().Set("X-RateLimit-Remaining", "4999")
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(`{"id": 123}`))
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: newETagTransport(nil)}
+
+	get := func() (int, string) {
+		t.Helper()
+		resp, err := client.Get(server.URL + "/repos/owner/repo/rulesets/123?includes_parents=true")
+		if err != nil {
+			t.Fatalf("GET failed: %v", err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+
+	get()
+	status, body := get()
+	if status != http.StatusOK || body != `{"id": 123}` {
+		t.Errorf("Expected cached 200 response, got %d %q", status, body)
+	}
+	if notModified != 1 {
+		t.Errorf("Expected one conditional request, got %d", notModified)
+	}
+
+	// A write to the same path drops the cached entry.
+	req, _ := http.NewRequest(http.MethodPut, server.URL+"/repos/owner/repo/rulesets/123", nil)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("PUT failed: %v", err)
+	}
+	resp.Body.Close()
+
+	get()
+	if gets != 3 || notModified != 1 {
+		t.Errorf("Expected an unconditional GET after the write, got %d GETs and %d 304s", gets, notModified)
+	}
+}
@@ -0,0 +1,200 @@
+package githubclient
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultMaxRetries is the number of times an idempotent request is
+	// retried after a rate limit or server error.
+	DefaultMaxRetries = 3
+
+	// DefaultRetryDelay is the base delay of the exponential backoff.
+	DefaultRetryDelay = time.Second
+
+	// secondaryRateLimitMinWait is the minimum wait GitHub asks for when a
+	// secondary rate limit response carries no Retry-After header.
+	secondaryRateLimitMinWait = time.Minute
+
+	// DefaultMaxRateLimitWait is the longest a request waits for a rate limit
+	// to reset. When the reset is further away, the rate limited response is
+	// returned, which go-github reports as a *github.RateLimitError or
+	// *github.AbuseRateLimitError.
+	DefaultMaxRateLimitWait = 5 * time.Minute
+)
+
+// rateLimitTransport waits out GitHub's primary and secondary rate limits and
+// retries idempotent requests that failed because of them or because of a
+// transient server error. When writeDelay is set, mutating requests are sent
+// one at a time with at least writeDelay between them.
+type rateLimitTransport struct {
+	base       http.RoundTripper
+	maxRetries int
+	retryDelay time.Duration
+	writeDelay time.Duration
+	maxWait    time.Duration
+	now        func() time.Time
+	sleep      func(ctx context.Context, d time.Duration) error
+
+	writeMu   sync.Mutex
+	lastWrite time.Time
+}
+
+func newRateLimitTransport(base http.RoundTripper, maxRetries int, retryDelay time.Duration) *rateLimitTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &rateLimitTransport{
+		base:       base,
+		maxRetries: maxRetries,
+		retryDelay: retryDelay,
+		maxWait:    DefaultMaxRateLimitWait,
+		now:        time.Now,
+		sleep:      sleepContext,
+	}
+}
+
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.writeDelay > 0 && isWrite(req) {
+		t.writeMu.Lock()
+		defer t.writeMu.Unlock()
+
+		if wait := t.lastWrite.Add(t.writeDelay).Sub(t.now()); wait > 0 {
+			if err := t.sleep(req.Context(), wait); err != nil {
+				return nil, err
+			}
+		}
+		defer func() { t.lastWrite = t.now() }()
+	}
+
+	for attempt := 0; ; attempt++ {
+		attemptReq := req
+		if attempt > 0 {
+			attemptReq = req.Clone(req.Context())
+			if req.GetBody != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					return nil, err
+				}
+				attemptReq.Body = body
+			}
+		}
+
+		resp, err := t.base.RoundTrip(attemptReq)
+		if err != nil {
+			return nil, err
+		}
+
+		if attempt >= t.maxRetries || !isIdempotent(req) {
+			return resp, nil
+		}
+
+		// Waiting longer than maxWait would block the provider, and the write
+		// lock when writes are serialized, for up to an hour.
+		wait, retry := t.retryAfter(resp, attempt)
+		if !retry || wait > t.maxWait {
+			return resp, nil
+		}
+
+		resp.Body.Close()
+		if err := t.sleep(req.Context(), wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// retryAfter decides whether resp should be retried and how long to wait
+// before doing so.
+func (t *rateLimitTransport) retryAfter(resp *http.Response, attempt int) (time.Duration, bool) {
+	switch resp.StatusCode {
+	case http.StatusForbidden, http.StatusTooManyRequests:
+		// Primary rate limit: wait until the window resets.
+		if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+			if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+				wait := time.Unix(reset, 0).Sub(t.now()) + time.Second
+				if wait < 0 {
+					wait = 0
+				}
+				return wait, true
+			}
+		}
+
+		// Secondary rate limit with an explicit wait.
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			return time.Duration(seconds)*time.Second + t.jitter(), true
+		}
+
+		if isSecondaryRateLimit(resp) {
+			wait := t.backoff(attempt)
+			if wait < secondaryRateLimitMinWait {
+				wait = secondaryRateLimitMinWait
+			}
+			return wait + t.jitter(), true
+		}
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return t.backoff(attempt) + t.jitter(), true
+	}
+
+	return 0, false
+}
+
+func (t *rateLimitTransport) backoff(attempt int) time.Duration {
+	return t.retryDelay * time.Duration(1<<attempt)
+}
+
+func (t *rateLimitTransport) jitter() time.Duration {
+	if t.retryDelay <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int64N(int64(t.retryDelay)))
+}
+
+// isSecondaryRateLimit reports whether a 403 or 429 response is GitHub's
+// secondary (abuse detection) rate limit. The body is restored for callers.
+func isSecondaryRateLimit(resp *http.Response) bool {
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return false
+	}
+
+	lower := strings.ToLower(string(body))
+	return strings.Contains(lower, "secondary rate limit") || strings.Contains(lower, "abuse")
+}
+
+func isWrite(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+	return true
+}
+
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+	}
+	return false
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,175 @@
+package githubclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRateLimitTransport(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		failures    int
+		respond     func(w http.ResponseWriter)
+		wantCalls   int32
+		wantStatus  int
+		wantMinWait time.Duration
+	}{
+		{
+			name:     "primary rate limit waits for reset",
+			method:   http.MethodGet,
+			failures: 1,
+			respond: func(w http.ResponseWriter) {
+				w.Header().Set("X-RateLimit-Remaining", "0")
+				w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(30*time.Second).Unix(), 10))
+				w.WriteHeader(http.StatusForbidden)
+			},
+			wantCalls:   2,
+			wantStatus:  http.StatusOK,
+			wantMinWait: 29 * time.Second,
+		},
+		{
+			name:     "primary rate limit resetting after the max wait is returned",
+			method:   http.MethodGet,
+			failures: 1,
+			respond: func(w http.ResponseWriter) {
+				w.Header().Set("X-RateLimit-Remaining", "0")
+				w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10))
+				w.WriteHeader(http.StatusForbidden)
+			},
+			wantCalls:  1,
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:     "secondary rate limit honors Retry-After",
+			method:   http.MethodPut,
+			failures: 1,
+			respond: func(w http.ResponseWriter) {
+				w.Header().Set("Retry-After", "5")
+				w.WriteHeader(http.StatusForbidden)
+			},
+			wantCalls:   2,
+			wantStatus:  http.StatusOK,
+			wantMinWait: 5 * time.Second,
+		},
+		{
+			name:     "secondary rate limit without Retry-After waits a minute",
+			method:   http.MethodGet,
+			failures: 1,
+			respond: func(w http.ResponseWriter) {
+				w.WriteHeader(http.StatusForbidden)
+				w.Write([]byte(`{"message": "You have exceeded a secondary rate limit."}`))
+			},
+			wantCalls:   2,
+			wantStatus:  http.StatusOK,
+			wantMinWait: time.Minute,
+		},
+		{
+			name:     "gives up after max retries",
+			method:   http.MethodGet,
+			failures: 10,
+			respond: func(w http.ResponseWriter) {
+				w.WriteHeader(http.StatusBadGateway)
+			},
+			wantCalls:  4,
+			wantStatus: http.StatusBadGateway,
+		},
+		{
+			name:     "non-idempotent requests are not retried",
+			method:   http.MethodPost,
+			failures: 1,
+			respond: func(w http.ResponseWriter) {
+				w.Header().Set("Retry-After", "5")
+				w.WriteHeader(http.StatusForbidden)
+			},
+			wantCalls:  1,
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:     "permission errors are not retried",
+			method:   http.MethodGet,
+			failures: 1,
+			respond: func(w http.ResponseWriter) {
+				w.WriteHeader(http.StatusForbidden)
+				w.Write([]byte(`{"message": "Resource not accessible by integration"}`))
+			},
+			wantCalls:  1,
+			wantStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var calls int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if int(atomic.AddInt32(&calls, 1)) <= test.failures {
+					test.respond(w)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			var waited time.Duration
+			transport := newRateLimitTransport(nil, DefaultMaxRetries, time.Millisecond)
+			transport.sleep = func(_ context.Context, d time.Duration) error {
+				waited += d
+				return nil
+			}
+
+			req, _ := http.NewRequest(test.method, server.URL, strings.NewReader("{}"))
+			resp, err := transport.RoundTrip(req)
+			if err != nil {
+				t.Fatalf("RoundTrip failed: %v", err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != test.wantStatus {
+				t.Errorf("Expected status %d, got %d", test.wantStatus, resp.StatusCode)
+			}
+			if calls != test.wantCalls {
+				t.Errorf("Expected %d calls, got %d", test.wantCalls, calls)
+			}
+			if waited < test.wantMinWait {
+				t.Errorf("Expected to wait at least %s, waited %s", test.wantMinWait, waited)
+			}
+		})
+	}
+}
+
+func TestRateLimitTransportWriteDelay(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	var waits []time.Duration
+	transport := newRateLimitTransport(nil, DefaultMaxRetries, time.Millisecond)
+	transport.writeDelay = time.Second
+	transport.sleep = func(_ context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return nil
+	}
+
+	for _, method := range []string{http.MethodPut, http.MethodGet, http.MethodPut} {
+		req, _ := http.NewRequest(method, server.URL, nil)
+		resp, err := transport.RoundTrip(req)
+		if err != nil {
+			t.Fatalf("RoundTrip failed: %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	if len(waits) != 1 {
+		t.Fatalf("Expected only the second write to wait, got %v", waits)
+	}
+	if waits[0] <= 0 || waits[0] > time.Second {
+		t.Errorf("Expected a wait of up to 1s, got %s", waits[0])
+	}
+}
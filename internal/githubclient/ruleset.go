@@ -0,0 +1,249 @@
+package githubclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Ruleset is a ruleset as returned by the GitHub API. Unlike
+// github.RepositoryRuleset it keeps the raw JSON of every field and rule, so
+// rule types and parameters newer than the go-github version in use survive
+// a read-modify-write unchanged.
+type Ruleset struct {
+	fields map[string]json.RawMessage
+	rules  []*RulesetRule
+}
+
+// RulesetRule is a single entry of a ruleset's rules array.
+type RulesetRule struct {
+	fields map[string]json.RawMessage
+	params map[string]json.RawMessage
+
+	// raw is the rule exactly as received. It is written back as is unless
+	// the rule has been modified.
+	raw json.RawMessage
+}
+
+// NewRulesetRule creates a rule of ruleType. parameters is marshaled into the
+// rule's parameters object unless it is nil.
+func NewRulesetRule(ruleType string, parameters any) (*RulesetRule, error) {
+	typeJSON, _ := json.Marshal(ruleType)
+	rule := &RulesetRule{
+		fields: map[string]json.RawMessage{"type": typeJSON},
+	}
+
+	if parameters != nil {
+		data, err := json.Marshal(parameters)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &rule.params); err != nil {
+			return nil, fmt.Errorf("parameters of %s rule must be a JSON object: %w", ruleType, err)
+		}
+	}
+	return rule, nil
+}
+
+func (r *Ruleset) UnmarshalJSON(data []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+
+	var rules []json.RawMessage
+	if raw, ok := fields["rules"]; ok && string(raw) != "null" {
+		if err := json.Unmarshal(raw, &rules); err != nil {
+			return fmt.Errorf("failed to decode ruleset rules: %w", err)
+		}
+	}
+	delete(fields, "rules")
+
+	r.fields = fields
+	r.rules = nil
+	for _, raw := range rules {
+		rule := &RulesetRule{raw: raw}
+		if err := json.Unmarshal(raw, &rule.fields); err != nil {
+			return fmt.Errorf("failed to decode ruleset rule: %w", err)
+		}
+		if params, ok := rule.fields["parameters"]; ok && string(params) != "null" {
+			if err := json.Unmarshal(params, &rule.params); err != nil {
+				return fmt.Errorf("failed to decode parameters of %s rule: %w", rule.Type(), err)
+			}
+		}
+		r.rules = append(r.rules, rule)
+	}
+	return nil
+}
+
+func (r *Ruleset) MarshalJSON() ([]byte, error) {
+	fields := make(map[string]json.RawMessage, len(r.fields)+1)
+	for name, value := range r.fields {
+		fields[name] = value
+	}
+
+	rules := make([]json.RawMessage, 0, len(r.rules))
+	for _, rule := range r.rules {
+		data, err := rule.MarshalJSON()
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, data)
+	}
+
+	data, err := json.Marshal(rules)
+	if err != nil {
+		return nil, err
+	}
+	fields["rules"] = data
+
+	return json.Marshal(fields)
+}
+
+// Field decodes the top-level field name into v. It reports whether the
+// field was present.
+func (r *Ruleset) Field(name string, v any) (bool, error) {
+	raw, ok := r.fields[name]
+	if !ok || string(raw) == "null" {
+		return false, nil
+	}
+	return true, json.Unmarshal(raw, v)
+}
+
+// SetField replaces the top-level field name with v.
+func (r *Ruleset) SetField(name string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if r.fields == nil {
+		r.fields = make(map[string]json.RawMessage)
+	}
+	r.fields[name] = data
+	return nil
+}
+
+// UpdatedAt returns the time the ruleset was last changed, or the zero time
+// if GitHub did not report it.
+func (r *Ruleset) UpdatedAt() time.Time {
+	var updatedAt time.Time
+	if ok, err := r.Field("updated_at", &updatedAt); !ok || err != nil {
+		return time.Time{}
+	}
+	return updatedAt
+}
+
+// Rules returns the rules of the ruleset in order.
+func (r *Ruleset) Rules() []*RulesetRule {
+	return r.rules
+}
+
+// Rule returns the first rule of ruleType, or nil if there is none.
+func (r *Ruleset) Rule(ruleType string) *RulesetRule {
+	for _, rule := range r.rules {
+		if rule.Type() == ruleType {
+			return rule
+		}
+	}
+	return nil
+}
+
+// SetRule replaces the rule of the same type, or appends rule if the ruleset
+// has none.
+func (r *Ruleset) SetRule(rule *RulesetRule) {
+	for i, existing := range r.rules {
+		if existing.Type() == rule.Type() {
+			r.rules[i] = rule
+			return
+		}
+	}
+	r.rules = append(r.rules, rule)
+}
+
+// RemoveRule removes every rule of ruleType.
+func (r *Ruleset) RemoveRule(ruleType string) {
+	rules := r.rules[:0]
+	for _, rule := range r.rules {
+		if rule.Type() != ruleType {
+			rules = append(rules, rule)
+		}
+	}
+	r.rules = rules
+}
+
+// Clone returns a copy of the rule that can be modified independently.
+func (r *RulesetRule) Clone() *RulesetRule {
+	clone := &RulesetRule{raw: r.raw}
+	if r.fields != nil {
+		clone.fields = make(map[string]json.RawMessage, len(r.fields))
+		for name, value := range r.fields {
+			clone.fields[name] = value
+		}
+	}
+	if r.params != nil {
+		clone.params = make(map[string]json.RawMessage, len(r.params))
+		for name, value := range r.params {
+			clone.params[name] = value
+		}
+	}
+	return clone
+}
+
+// Type returns the rule type, e.g. "pull_request".
+func (r *RulesetRule) Type() string {
+	var ruleType string
+	_ = json.Unmarshal(r.fields["type"], &ruleType)
+	return ruleType
+}
+
+// Parameter decodes the parameter name into v. It reports whether the
+// parameter was present.
+func (r *RulesetRule) Parameter(name string, v any) (bool, error) {
+	raw, ok := r.params[name]
+	if !ok || string(raw) == "null" {
+		return false, nil
+	}
+	return true, json.Unmarshal(raw, v)
+}
+
+// SetParameter sets the parameter name to v, leaving every other parameter
+// untouched.
+func (r *RulesetRule) SetParameter(name string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if r.params == nil {
+		r.params = make(map[string]json.RawMessage)
+	}
+	r.params[name] = data
+	r.raw = nil
+	return nil
+}
+
+// DeleteParameter removes the parameter name.
+func (r *RulesetRule) DeleteParameter(name string) {
+	if _, ok := r.params[name]; ok {
+		delete(r.params, name)
+		r.raw = nil
+	}
+}
+
+func (r *RulesetRule) MarshalJSON() ([]byte, error) {
+	if r.raw != nil {
+		return r.raw, nil
+	}
+
+	fields := make(map[string]json.RawMessage, len(r.fields)+1)
+	for name, value := range r.fields {
+		fields[name] = value
+	}
+	if r.params != nil {
+		params, err := json.Marshal(r.params)
+		if err != nil {
+			return nil, err
+		}
+		fields["parameters"] = params
+	}
+	return json.Marshal(fields)
+}
@@ -0,0 +1,244 @@
+package githubclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/google/go-github/v74/github"
+)
+
+// RulesetKey identifies a repository ruleset, or an organization ruleset of
+// Owner when Repo is empty.
+type RulesetKey struct {
+	Owner string
+	Repo  string
+	ID    int64
+}
+
+func (k RulesetKey) String() string {
+	if k.Repo == "" {
+		return fmt.Sprintf("%s/%d", k.Owner, k.ID)
+	}
+	return fmt.Sprintf("%s/%s/%d", k.Owner, k.Repo, k.ID)
+}
+
+func (k RulesetKey) path() string {
+	if k.Repo == "" {
+		return fmt.Sprintf("orgs/%v/rulesets/%v", k.Owner, k.ID)
+	}
+	return fmt.Sprintf("repos/%v/%v/rulesets/%v", k.Owner, k.Repo, k.ID)
+}
+
+// rulesetCache shares ruleset reads between resources of one provider
+// instance. Concurrent reads of the same key are merged into one request.
+type rulesetCache struct {
+	mu      sync.Mutex
+	entries map[RulesetKey]*rulesetCall
+}
+
+type rulesetCall struct {
+	done    chan struct{}
+	ruleset *Ruleset
+	err     error
+}
+
+func (c *rulesetCache) get(key RulesetKey, fetch func() (*Ruleset, error)) (*Ruleset, error) {
+	c.mu.Lock()
+	if c.entries == nil {
+		c.entries = make(map[RulesetKey]*rulesetCall)
+	}
+	call, ok := c.entries[key]
+	if !ok {
+		call = &rulesetCall{done: make(chan struct{})}
+		c.entries[key] = call
+	}
+	c.mu.Unlock()
+
+	if !ok {
+		call.ruleset, call.err = fetch()
+		if call.err != nil {
+			// Failed reads are not cached, the next caller tries again.
+			c.mu.Lock()
+			if c.entries[key] == call {
+				delete(c.entries, key)
+			}
+			c.mu.Unlock()
+		}
+		close(call.done)
+	} else {
+		<-call.done
+	}
+
+	if call.err != nil {
+		return nil, call.err
+	}
+	return call.ruleset.clone()
+}
+
+func (c *rulesetCache) invalidate(key RulesetKey) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, key)
+}
+
+// keyedMutex serializes work per ruleset.
+type keyedMutex struct {
+	mu    sync.Mutex
+	locks map[RulesetKey]*sync.Mutex
+}
+
+func (m *keyedMutex) lock(key RulesetKey) func() {
+	m.mu.Lock()
+	if m.locks == nil {
+		m.locks = make(map[RulesetKey]*sync.Mutex)
+	}
+	l, ok := m.locks[key]
+	if !ok {
+		l = &sync.Mutex{}
+		m.locks[key] = l
+	}
+	m.mu.Unlock()
+
+	l.Lock()
+	return l.Unlock
+}
+
+// GetRuleset returns the ruleset identified by key. The result is shared with
+// every other caller for the same key until the ruleset is updated through
+// UpdateRuleset. The returned ruleset is a copy that callers may modify.
+func (c *Client) GetRuleset(ctx context.Context, key RulesetKey) (*Ruleset, error) {
+	return c.rulesets.get(key, func() (*Ruleset, error) {
+		u := key.path()
+		if key.Repo != "" {
+			u += "?includes_parents=true"
+		}
+		req, err := c.NewRequest("GET", u, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		ruleset := new(Ruleset)
+		if _, err := c.Do(ctx, req, ruleset); err != nil {
+			return nil, err
+		}
+		return ruleset, nil
+	})
+}
+
+// FindRulesetID returns the ID of the ruleset named name on owner/repo. Only
+// rulesets defined on the repository itself are considered; rulesets
+// inherited from the organization cannot be modified through the repository.
+// A *RulesetNameError is returned unless exactly one ruleset matches.
+func (c *Client) FindRulesetID(ctx context.Context, owner, repo, name string) (int64, error) {
+	opts := &github.RepositoryListRulesetsOptions{
+		IncludesParents: github.Ptr(false),
+		ListOptions:     github.ListOptions{PerPage: 100},
+	}
+
+	var matches []int64
+	for {
+		rulesets, resp, err := c.Repositories.GetAllRulesets(ctx, owner, repo, opts)
+		if err != nil {
+			return 0, err
+		}
+		for _, ruleset := range rulesets {
+			if ruleset.Name == name {
+				matches = append(matches, ruleset.GetID())
+			}
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	if len(matches) != 1 {
+		return 0, &RulesetNameError{Owner: owner, Repo: repo, Name: name, Matches: matches}
+	}
+	return matches[0], nil
+}
+
+// UpdateRuleset replaces the ruleset identified by key and drops its cached
+// copy. Fields and rules unknown to this provider are sent back unchanged.
+func (c *Client) UpdateRuleset(ctx context.Context, key RulesetKey, ruleset *Ruleset) (*Ruleset, error) {
+	defer c.rulesets.invalidate(key)
+
+	req, err := c.NewRequest("PUT", key.path(), ruleset)
+	if err != nil {
+		return nil, err
+	}
+
+	updated := new(Ruleset)
+	if _, err := c.Do(ctx, req, updated); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// maxRulesetWriteAttempts bounds how often ModifyRuleset re-applies a
+// modification when the ruleset keeps changing underneath it.
+const maxRulesetWriteAttempts = 3
+
+// ModifyRuleset applies modify to the ruleset identified by key and writes
+// the result back. Modifications of the same ruleset run one at a time, so
+// concurrent resources of this provider never overwrite each other's changes.
+//
+// modify is first applied to the ruleset as this provider last read it, which
+// is usually the copy read during refresh. Right before writing, the ruleset
+// is read again and its updated_at compared with the version modify was
+// applied to. If someone else changed the ruleset in the meantime, e.g. in the
+// UI between plan and apply, modify is applied again to the new version, so
+// modify may be called more than once. The re-read is revalidated with the
+// ETag and costs no rate limit when the ruleset is unchanged. After
+// maxRulesetWriteAttempts mismatches a *RulesetConflictError is returned.
+//
+// GitHub has no conditional update for rulesets, so a change that lands
+// between the last re-read and the write is still overwritten.
+//
+// The ruleset returned by GitHub after the write is returned.
+func (c *Client) ModifyRuleset(ctx context.Context, key RulesetKey, modify func(*Ruleset) error) (*Ruleset, error) {
+	unlock := c.rulesetLocks.lock(key)
+	defer unlock()
+
+	ruleset, err := c.GetRuleset(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	for attempt := 1; ; attempt++ {
+		base := ruleset.UpdatedAt()
+		if err := modify(ruleset); err != nil {
+			return nil, err
+		}
+
+		c.rulesets.invalidate(key)
+		current, err := c.GetRuleset(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		if current.UpdatedAt().Equal(base) {
+			return c.UpdateRuleset(ctx, key, ruleset)
+		}
+
+		if attempt >= maxRulesetWriteAttempts {
+			return nil, &RulesetConflictError{Key: key, Attempts: attempt}
+		}
+		ruleset = current
+	}
+}
+
+func (r *Ruleset) clone() (*Ruleset, error) {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+
+	copied := new(Ruleset)
+	if err := json.Unmarshal(data, copied); err != nil {
+		return nil, err
+	}
+	return copied, nil
+}
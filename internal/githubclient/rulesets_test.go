@@ -0,0 +1,277 @@
+package githubclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v74/github"
+)
+
+func newTestClient(t *testing.T, mux *http.ServeMux) *Client {
+	t.Helper()
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	client := github.NewClient(nil)
+	client.BaseURL, _ = client.BaseURL.Parse(server.URL + "/")
+	return &Client{Client: client, Owner: "owner"}
+}
+
+func TestGetRulesetSharesReads(t *testing.T) {
+	var gets int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/owner/repo/rulesets/123", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			atomic.AddInt32(&gets, 1)
+			// Keep the request in flight long enough for callers to pile up.
+			time.Sleep(50 * time.Millisecond)
+		}
+		fmt.Fprint(w, `{"id": 123, "name": "test-ruleset", "target": "branch", "enforcement": "active"}`)
+	})
+	client := newTestClient(t, mux)
+	key := RulesetKey{Owner: "owner", Repo: "repo", ID: 123}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.GetRuleset(context.Background(), key); err != nil {
+				t.Errorf("GetRuleset failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	ruleset, err := client.GetRuleset(context.Background(), key)
+	if err != nil {
+		t.Fatalf("GetRuleset failed: %v", err)
+	}
+	if gets != 1 {
+		t.Errorf("Expected a single GET, got %d", gets)
+	}
+
+	// Callers get their own copy.
+	ruleset.SetField("name", "modified")
+	cached, _ := client.GetRuleset(context.Background(), key)
+	var name string
+	cached.Field("name", &name)
+	if name != "test-ruleset" {
+		t.Errorf("Expected cached ruleset to be unaffected, got name %q", name)
+	}
+
+	if _, err := client.UpdateRuleset(context.Background(), key, ruleset); err != nil {
+		t.Fatalf("UpdateRuleset failed: %v", err)
+	}
+	if _, err := client.GetRuleset(context.Background(), key); err != nil {
+		t.Fatalf("GetRuleset failed: %v", err)
+	}
+	if gets != 2 {
+		t.Errorf("Expected the update to invalidate the cache, got %d GETs", gets)
+	}
+}
+
+func TestModifyRulesetSerializesWrites(t *testing.T) {
+	var mu sync.Mutex
+	stored := []byte(`{"id": 123, "name": "test-ruleset", "target": "branch", "enforcement": "active"}`)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/owner/repo/rulesets/123", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if r.Method == "PUT" {
+			stored, _ = io.ReadAll(r.Body)
+		}
+		w.Write(stored)
+	})
+	client := newTestClient(t, mux)
+	key := RulesetKey{Owner: "owner", Repo: "repo", ID: 123}
+
+	var wg sync.WaitGroup
+	for i := int64(0); i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := client.ModifyRuleset(context.Background(), key, func(ruleset *Ruleset) error {
+				var actors []*github.BypassActor
+				if _, err := ruleset.Field("bypass_actors", &actors); err != nil {
+					return err
+				}
+				actors = append(actors, &github.BypassActor{ActorID: github.Ptr(i)})
+				return ruleset.SetField("bypass_actors", actors)
+			})
+			if err != nil {
+				t.Errorf("ModifyRuleset failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	ruleset, err := client.GetRuleset(context.Background(), key)
+	if err != nil {
+		t.Fatalf("GetRuleset failed: %v", err)
+	}
+	var actors []*github.BypassActor
+	ruleset.Field("bypass_actors", &actors)
+	if len(actors) != 10 {
+		t.Errorf("Expected all 10 modifications to be kept, got %d", len(actors))
+	}
+}
+
+func TestModifyRulesetDetectsConcurrentChanges(t *testing.T) {
+	tests := []struct {
+		name         string
+		changes      int
+		wantModifies int
+		wantPuts     int32
+		wantConflict bool
+	}{
+		{"unchanged", 0, 1, 1, false},
+		{"changed once", 1, 2, 1, false},
+		{"keeps changing", 10, maxRulesetWriteAttempts, 0, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var puts int32
+			version := 0
+			mux := http.NewServeMux()
+			mux.HandleFunc("/repos/owner/repo/rulesets/123", func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == "PUT" {
+					atomic.AddInt32(&puts, 1)
+				}
+				fmt.Fprintf(w, `{"id": 123, "name": "test-ruleset", "updated_at": "2025-01-01T00:00:%02dZ"}`, version)
+				// Someone else changes the ruleset right after each read
+				// until the configured number of changes happened.
+				if r.Method == "GET" && version < test.changes {
+					version++
+				}
+			})
+			client := newTestClient(t, mux)
+			key := RulesetKey{Owner: "owner", Repo: "repo", ID: 123}
+
+			modifies := 0
+			_, err := client.ModifyRuleset(context.Background(), key, func(ruleset *Ruleset) error {
+				modifies++
+				return nil
+			})
+
+			var conflictErr *RulesetConflictError
+			if test.wantConflict != errors.As(err, &conflictErr) {
+				t.Fatalf("Expected conflict %v, got %v", test.wantConflict, err)
+			}
+			if !test.wantConflict && err != nil {
+				t.Fatalf("ModifyRuleset failed: %v", err)
+			}
+			if modifies != test.wantModifies {
+				t.Errorf("Expected %d modifications, got %d", test.wantModifies, modifies)
+			}
+			if puts != test.wantPuts {
+				t.Errorf("Expected %d PUTs, got %d", test.wantPuts, puts)
+			}
+		})
+	}
+}
+
+func TestModifyRulesetReappliesChangesSinceRefresh(t *testing.T) {
+	var gets, puts int32
+	version := 1
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/owner/repo/rulesets/123", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "PUT" {
+			atomic.AddInt32(&puts, 1)
+		} else {
+			atomic.AddInt32(&gets, 1)
+		}
+		fmt.Fprintf(w, `{"id": 123, "name": "version-%d", "updated_at": "2025-01-01T00:00:%02dZ"}`, version, version)
+	})
+	client := newTestClient(t, mux)
+	key := RulesetKey{Owner: "owner", Repo: "repo", ID: 123}
+
+	// Read during refresh, then changed outside of the provider.
+	if _, err := client.GetRuleset(context.Background(), key); err != nil {
+		t.Fatalf("GetRuleset failed: %v", err)
+	}
+	version = 2
+
+	var modified []string
+	_, err := client.ModifyRuleset(context.Background(), key, func(ruleset *Ruleset) error {
+		var name string
+		_, err := ruleset.Field("name", &name)
+		modified = append(modified, name)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("ModifyRuleset failed: %v", err)
+	}
+
+	if want := []string{"version-1", "version-2"}; !slices.Equal(modified, want) {
+		t.Errorf("Expected modify to be applied to %v, got %v", want, modified)
+	}
+	if gets != 3 || puts != 1 {
+		t.Errorf("Expected 3 GETs and 1 PUT, got %d GETs and %d PUTs", gets, puts)
+	}
+}
+
+func TestFindRulesetID(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/owner/repo/rulesets", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("includes_parents"); got != "false" {
+			t.Errorf("Expected includes_parents=false, got %q", got)
+		}
+		switch r.URL.Query().Get("page") {
+		case "", "1":
+			w.Header().Set("Link", fmt.Sprintf(`<http://%s%s?page=2>; rel="next"`, r.Host, r.URL.Path))
+			fmt.Fprint(w, `[{"id": 1, "name": "main"}, {"id": 2, "name": "release"}]`)
+		default:
+			fmt.Fprint(w, `[{"id": 3, "name": "release"}, {"id": 4, "name": "tags"}]`)
+		}
+	})
+	client := newTestClient(t, mux)
+
+	tests := []struct {
+		name        string
+		ruleset     string
+		wantID      int64
+		wantMatches []int64
+	}{
+		{name: "single match", ruleset: "main", wantID: 1},
+		{name: "match on a later page", ruleset: "tags", wantID: 4},
+		{name: "no match", ruleset: "missing", wantMatches: nil},
+		{name: "multiple matches", ruleset: "release", wantMatches: []int64{2, 3}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			id, err := client.FindRulesetID(context.Background(), "owner", "repo", test.ruleset)
+			if test.wantID != 0 {
+				if err != nil {
+					t.Fatalf("FindRulesetID failed: %v", err)
+				}
+				if id != test.wantID {
+					t.Errorf("Expected ID %d, got %d", test.wantID, id)
+				}
+				return
+			}
+
+			var nameErr *RulesetNameError
+			if !errors.As(err, &nameErr) {
+				t.Fatalf("Expected *RulesetNameError, got %v", err)
+			}
+			if fmt.Sprint(nameErr.Matches) != fmt.Sprint(test.wantMatches) {
+				t.Errorf("Expected matches %v, got %v", test.wantMatches, nameErr.Matches)
+			}
+		})
+	}
+}
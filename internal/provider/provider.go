@@ -2,11 +2,19 @@ package provider
 
 import (
 	"context"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
 	"os"
 	"strings"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
 	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
@@ -15,12 +23,43 @@ import (
 	"github.com/knowledge-work/terraform-provider-kw-github/internal/githubclient"
 )
 
+// Drift modes control what resources do when Read finds that the managed
+// settings were changed outside of Terraform.
+const (
+	// driftModeRestore writes the configured settings back during Read.
+	driftModeRestore = "restore"
+	// driftModeReport records the actual settings so the plan shows a diff.
+	driftModeReport = "report"
+	// driftModeIgnore keeps the configured settings in state.
+	driftModeIgnore = "ignore"
+)
+
+var driftModes = []string{driftModeRestore, driftModeReport, driftModeIgnore}
+
+// resolveDriftMode returns the drift mode configured on a resource, falling
+// back to the provider default.
+func resolveDriftMode(configured types.String, providerDefault string) string {
+	if !configured.IsNull() && !configured.IsUnknown() {
+		return configured.ValueString()
+	}
+	if providerDefault != "" {
+		return providerDefault
+	}
+	return driftModeRestore
+}
+
 func New() provider.Provider {
 	return &kwgithubProvider{}
 }
 
 type kwgithubProvider struct{}
 
+// providerData is passed from the provider to resources in Configure.
+type providerData struct {
+	client    *githubclient.Client
+	driftMode string
+}
+
 func (p *kwgithubProvider) Metadata(_ context.Context, _ provider.MetadataRequest, resp *provider.MetadataResponse) {
 	resp.TypeName = "kwgithub"
 }
@@ -42,6 +81,34 @@ func (p *kwgithubProvider) Schema(_ context.Context, _ provider.SchemaRequest, r
 				Optional:    true,
 				Description: "GitHub base URL. Defaults to https://api.github.com. Can also be set via GITHUB_BASE_URL environment variable.",
 			},
+			"max_retries": schema.Int64Attribute{
+				Optional:    true,
+				Description: "Number of times an idempotent request is retried after hitting a primary or secondary rate limit or a transient server error. Defaults to 3.",
+				Validators: []validator.Int64{
+					int64validator.AtLeast(0),
+				},
+			},
+			"retry_delay_ms": schema.Int64Attribute{
+				Optional:    true,
+				Description: "Base delay in milliseconds of the exponential backoff between retries. Primary rate limits wait until the limit resets, unless that is more than 5 minutes away, in which case the request fails with a rate limit error. Defaults to 1000.",
+				Validators: []validator.Int64{
+					int64validator.AtLeast(0),
+				},
+			},
+			"drift_mode": schema.StringAttribute{
+				Optional:    true,
+				Description: "Default drift handling for resources that do not set drift_mode. 'restore' writes the configured settings back during refresh, 'report' shows the drift as a plan diff and fixes it on apply, 'ignore' does nothing. Defaults to 'restore'.",
+				Validators: []validator.String{
+					stringvalidator.OneOf(driftModes...),
+				},
+			},
+			"write_delay_ms": schema.Int64Attribute{
+				Optional:    true,
+				Description: "Minimum delay in milliseconds between mutating API calls. When set, mutating calls are sent one at a time. Defaults to 0.",
+				Validators: []validator.Int64{
+					int64validator.AtLeast(0),
+				},
+			},
 		},
 		Blocks: map[string]schema.Block{
 			"app_auth": schema.ListNestedBlock{
@@ -57,12 +124,19 @@ func (p *kwgithubProvider) Schema(_ context.Context, _ provider.SchemaRequest, r
 						},
 						"installation_id": schema.StringAttribute{
 							Optional:    true,
-							Description: "GitHub App installation ID. Can also be set via GITHUB_APP_INSTALLATION_ID environment variable.",
+							Description: "GitHub App installation ID. Can also be set via GITHUB_APP_INSTALLATION_ID environment variable. If omitted, the installation of the app on owner is looked up automatically.",
 						},
 						"pem_file": schema.StringAttribute{
 							Optional:    true,
 							Sensitive:   true,
 							Description: "GitHub App private key PEM file contents. Can also be set via GITHUB_APP_PEM_FILE environment variable.",
+							Validators: []validator.String{
+								stringvalidator.ConflictsWith(path.MatchRelative().AtParent().AtName("pem_file_path")),
+							},
+						},
+						"pem_file_path": schema.StringAttribute{
+							Optional:    true,
+							Description: "Path to the GitHub App private key PEM file. Can also be set via GITHUB_APP_PEM_FILE_PATH environment variable. Conflicts with pem_file.",
 						},
 					},
 				},
@@ -76,10 +150,15 @@ func (p *kwgithubProvider) Configure(ctx context.Context, req provider.Configure
 		Token         types.String `tfsdk:"token"`
 		Owner         types.String `tfsdk:"owner"`
 		GithubBaseURL types.String `tfsdk:"github_base_url"`
+		MaxRetries    types.Int64  `tfsdk:"max_retries"`
+		RetryDelayMs  types.Int64  `tfsdk:"retry_delay_ms"`
+		WriteDelayMs  types.Int64  `tfsdk:"write_delay_ms"`
+		DriftMode     types.String `tfsdk:"drift_mode"`
 		AppAuth       []struct {
 			ID             types.String `tfsdk:"id"`
 			InstallationID types.String `tfsdk:"installation_id"`
 			PemFile        types.String `tfsdk:"pem_file"`
+			PemFilePath    types.String `tfsdk:"pem_file_path"`
 		} `tfsdk:"app_auth"`
 	}
 
@@ -107,6 +186,22 @@ func (p *kwgithubProvider) Configure(ctx context.Context, req provider.Configure
 		return
 	}
 
+	maxRetries := githubclient.DefaultMaxRetries
+	if !config.MaxRetries.IsNull() && !config.MaxRetries.IsUnknown() {
+		maxRetries = int(config.MaxRetries.ValueInt64())
+	}
+	retryDelay := githubclient.DefaultRetryDelay
+	if !config.RetryDelayMs.IsNull() && !config.RetryDelayMs.IsUnknown() {
+		retryDelay = time.Duration(config.RetryDelayMs.ValueInt64()) * time.Millisecond
+	}
+	clientOpts := []githubclient.Option{
+		githubclient.WithRetry(maxRetries, retryDelay),
+	}
+	if !config.WriteDelayMs.IsNull() && !config.WriteDelayMs.IsUnknown() {
+		writeDelay := time.Duration(config.WriteDelayMs.ValueInt64()) * time.Millisecond
+		clientOpts = append(clientOpts, githubclient.WithWriteDelay(writeDelay))
+	}
+
 	var token string
 	var client *githubclient.Client
 
@@ -130,25 +225,34 @@ func (p *kwgithubProvider) Configure(ctx context.Context, req provider.Configure
 		if !appAuth.PemFile.IsNull() && !appAuth.PemFile.IsUnknown() {
 			pemFile = appAuth.PemFile.ValueString()
 		}
-		if pemFile == "" {
+		pemFilePath := ""
+		if !appAuth.PemFilePath.IsNull() && !appAuth.PemFilePath.IsUnknown() {
+			pemFilePath = appAuth.PemFilePath.ValueString()
+		}
+		if pemFile == "" && pemFilePath == "" {
 			pemFile = os.Getenv("GITHUB_APP_PEM_FILE")
 		}
+		if pemFile == "" && pemFilePath == "" {
+			pemFilePath = os.Getenv("GITHUB_APP_PEM_FILE_PATH")
+		}
 
-		if appID == "" || installationID == "" || pemFile == "" {
+		if appID == "" || (pemFile == "" && pemFilePath == "") {
 			resp.Diagnostics.AddError(
 				"Incomplete GitHub App configuration",
-				"app_auth.id, app_auth.installation_id, and app_auth.pem_file must all be set either in configuration or via environment variables (GITHUB_APP_ID, GITHUB_APP_INSTALLATION_ID, GITHUB_APP_PEM_FILE)",
+				"app_auth.id and either app_auth.pem_file or app_auth.pem_file_path must be set either in configuration or via environment variables (GITHUB_APP_ID, GITHUB_APP_PEM_FILE, GITHUB_APP_PEM_FILE_PATH)",
 			)
 			return
 		}
 
-		pemFile = strings.Replace(pemFile, `\n`, "\n", -1)
-		client = githubclient.NewClientWithApp(appID, installationID, pemFile, baseURL, owner)
-		if client == nil {
-			resp.Diagnostics.AddError(
-				"Failed to create GitHub App client",
-				"Unable to generate access token from GitHub App credentials",
-			)
+		pemData, err := loadAppPrivateKey(pemFile, pemFilePath)
+		if err != nil {
+			resp.Diagnostics.AddError("Invalid GitHub App private key", err.Error())
+			return
+		}
+
+		client, err = githubclient.NewClientWithApp(appID, installationID, pemData, baseURL, owner, clientOpts...)
+		if err != nil {
+			resp.Diagnostics.AddError(appAuthErrorDiagnostic(err))
 			return
 		}
 	} else {
@@ -162,10 +266,95 @@ func (p *kwgithubProvider) Configure(ctx context.Context, req provider.Configure
 			resp.Diagnostics.AddError("Missing authentication", "Either token or app_auth must be configured")
 			return
 		}
-		client = githubclient.NewClient(token, baseURL, owner)
+		client = githubclient.NewClient(token, baseURL, owner, clientOpts...)
+	}
+
+	driftMode := driftModeRestore
+	if !config.DriftMode.IsNull() && !config.DriftMode.IsUnknown() {
+		driftMode = config.DriftMode.ValueString()
+	}
+
+	resp.ResourceData = &providerData{
+		client:    client,
+		driftMode: driftMode,
+	}
+}
+
+// loadAppPrivateKey returns the PEM encoded GitHub App private key, either from
+// the inline pemFile contents or read from pemFilePath.
+func loadAppPrivateKey(pemFile, pemFilePath string) (string, error) {
+	if pemFile != "" {
+		pemFile = strings.Replace(pemFile, `\n`, "\n", -1)
+		if block, _ := pem.Decode([]byte(pemFile)); block != nil {
+			return pemFile, nil
+		}
+		if _, err := os.Stat(pemFile); err == nil {
+			return "", fmt.Errorf("app_auth.pem_file must contain the PEM encoded key, but it looks like a file path; use app_auth.pem_file_path or GITHUB_APP_PEM_FILE_PATH instead")
+		}
+		return "", fmt.Errorf("app_auth.pem_file is neither PEM encoded key contents nor a readable file path")
 	}
 
-	resp.ResourceData = client
+	data, err := os.ReadFile(pemFilePath)
+	if err != nil {
+		return "", fmt.Errorf("app_auth.pem_file_path %q could not be read: %v", pemFilePath, err)
+	}
+	if block, _ := pem.Decode(data); block == nil {
+		return "", fmt.Errorf("app_auth.pem_file_path %q does not contain a PEM encoded key", pemFilePath)
+	}
+	return string(data), nil
+}
+
+// appAuthErrorDiagnostic maps a GitHub App authentication failure to a
+// diagnostic summary and a detail with a hint on how to fix it.
+func appAuthErrorDiagnostic(err error) (string, string) {
+	var authErr *githubclient.AppAuthError
+
+	switch {
+	case errors.Is(err, githubclient.ErrInvalidPEM):
+		return "Invalid GitHub App private key",
+			"app_auth.pem_file does not contain a PEM encoded key. " +
+				"If the key is passed through an environment variable, make sure line breaks are preserved or escaped as \\n. " +
+				"Error: " + err.Error()
+	case errors.Is(err, githubclient.ErrInvalidPrivateKey):
+		return "Invalid GitHub App private key",
+			"app_auth.pem_file could not be parsed as a PKCS#1 or PKCS#8 private key. " +
+				"Generate a new private key from the GitHub App settings page. Error: " + err.Error()
+	case errors.Is(err, githubclient.ErrNotRSAKey):
+		return "Unsupported GitHub App private key",
+			"GitHub App private keys must be RSA keys. Use the private key generated by GitHub for this app."
+	case errors.Is(err, githubclient.ErrInvalidAppID):
+		return "Invalid GitHub App ID",
+			"app_auth.id must be the numeric App ID shown on the GitHub App settings page, not the Client ID. " +
+				"Error: " + err.Error()
+	case errors.As(err, &authErr) && authErr.IsClockSkew():
+		return "GitHub App token rejected due to clock skew",
+			"GitHub rejected the app JWT because its issued-at or expiration time is invalid. " +
+				"Make sure the system clock of the machine running Terraform is synchronized. Error: " + err.Error()
+	case errors.As(err, &authErr) && authErr.StatusCode == http.StatusUnauthorized:
+		return "GitHub App authentication failed",
+			"GitHub rejected the app JWT. Make sure app_auth.id and app_auth.pem_file belong to the same GitHub App " +
+				"and that the private key has not been revoked. Error: " + err.Error()
+	case errors.As(err, &authErr) && authErr.Owner != "" && authErr.StatusCode == http.StatusNotFound:
+		return "GitHub App installation not found",
+			fmt.Sprintf("The GitHub App is not installed on %q, so its installation could not be looked up. ", authErr.Owner) +
+				"Install the app on the owner, or set app_auth.installation_id. Error: " + err.Error()
+	case errors.As(err, &authErr) && authErr.Owner != "":
+		return "GitHub App installation lookup failed",
+			fmt.Sprintf("GitHub refused to look up the installation of the GitHub App on %q. ", authErr.Owner) +
+				"Make sure app_auth.id and app_auth.pem_file belong to the same GitHub App, or set app_auth.installation_id. " +
+				"Error: " + err.Error()
+	case errors.As(err, &authErr) && authErr.StatusCode == http.StatusNotFound:
+		return "GitHub App installation not found",
+			"No installation with the given app_auth.installation_id exists for this GitHub App. " +
+				"Make sure the installation ID is correct. Error: " + err.Error()
+	case errors.As(err, &authErr) && authErr.StatusCode == http.StatusForbidden:
+		return "GitHub App installation is not accessible",
+			"GitHub refused to issue an installation token. The installation may be suspended " +
+				"or the app may lack the required permissions. Error: " + err.Error()
+	default:
+		return "Failed to create GitHub App client",
+			"Unable to generate access token from GitHub App credentials: " + err.Error()
+	}
 }
 
 func (p *kwgithubProvider) DataSources(_ context.Context) []func() datasource.DataSource {
@@ -175,5 +364,13 @@ func (p *kwgithubProvider) DataSources(_ context.Context) []func() datasource.Da
 func (p *kwgithubProvider) Resources(_ context.Context) []func() resource.Resource {
 	return []func() resource.Resource{
 		NewRulesetAllowedMergeMethodsResource,
+		NewOrganizationRulesetAllowedMergeMethodsResource,
+		NewRulesetPullRequestOverlayResource,
+		NewRulesetBypassActorResource,
+		NewRulesetRequiredStatusCheckResource,
+		NewRulesetMergeQueueResource,
+		NewRulesetPushRestrictionResource,
+		NewRulesetPatternRuleResource,
+		NewRulesetRuleTogglesResource,
 	}
 }
@@ -0,0 +1,311 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/knowledge-work/terraform-provider-kw-github/internal/githubclient"
+)
+
+func NewOrganizationRulesetAllowedMergeMethodsResource() resource.Resource {
+	return &organizationRulesetAllowedMergeMethodsResource{}
+}
+
+type organizationRulesetAllowedMergeMethodsResource struct {
+	client           *githubclient.Client
+	defaultDriftMode string
+}
+
+type organizationRulesetAllowedMergeMethodsResourceModel struct {
+	Organization        types.String `tfsdk:"organization"`
+	RulesetID           types.String `tfsdk:"ruleset_id"`
+	AllowedMergeMethods types.Set    `tfsdk:"allowed_merge_methods"`
+	ForceUpdate         types.String `tfsdk:"force_update"`
+	DriftMode           types.String `tfsdk:"drift_mode"`
+	OnDestroy           types.String `tfsdk:"on_destroy"`
+	RulesetFingerprint  types.String `tfsdk:"ruleset_fingerprint"`
+	ID                  types.String `tfsdk:"id"`
+}
+
+func (r *organizationRulesetAllowedMergeMethodsResource) Metadata(
+	_ context.Context,
+	req resource.MetadataRequest,
+	resp *resource.MetadataResponse,
+) {
+	resp.TypeName = req.ProviderTypeName + "_organization_ruleset_allowed_merge_methods"
+}
+
+func (r *organizationRulesetAllowedMergeMethodsResource) Schema(
+	_ context.Context,
+	_ resource.SchemaRequest,
+	resp *resource.SchemaResponse,
+) {
+	resp.Schema = schema.Schema{
+		Description: "Manages allowed merge methods for a GitHub organization ruleset.",
+		Attributes: map[string]schema.Attribute{
+			"organization": schema.StringAttribute{
+				Required:    true,
+				Description: "The name of the organization that owns the ruleset.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"ruleset_id": schema.StringAttribute{
+				Required:    true,
+				Description: "The ID of the organization ruleset to manage.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"allowed_merge_methods": schema.SetAttribute{
+				ElementType: types.StringType,
+				Required:    true,
+				Description: "Set of allowed merge methods. Valid values are: 'merge', 'squash', 'rebase'.",
+				Validators: []validator.Set{
+					setvalidator.SizeAtLeast(1),
+					setvalidator.ValueStringsAre(stringvalidator.OneOf(allMergeMethods...)),
+				},
+			},
+			"force_update": schema.StringAttribute{
+				Optional:    true,
+				Description: "Arbitrary value to force an update when dependent resources change in the same apply. Changes made to the ruleset outside of Terraform are detected through ruleset_fingerprint without it.",
+			},
+			"drift_mode": driftModeAttribute("the merge methods were changed"),
+			"on_destroy": schema.StringAttribute{
+				Optional:    true,
+				Description: "What to do with the merge methods when the resource is destroyed. 'restore_previous' (default) restores the methods the ruleset allowed before Terraform managed them, 'allow_all' allows merge, squash and rebase, 'leave_as_is' keeps the current methods.",
+				Validators: []validator.String{
+					stringvalidator.OneOf(onDestroyModes...),
+				},
+			},
+			"ruleset_fingerprint": schema.StringAttribute{
+				Computed:    true,
+				Description: "Hash of the ruleset's pull_request rule, excluding the allowed merge methods and the parameters kwgithub_ruleset_pull_request_overlay manages. In restore drift mode, an update is planned when it changes outside of Terraform.",
+			},
+			"id": schema.StringAttribute{
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+		},
+	}
+}
+
+func (r *organizationRulesetAllowedMergeMethodsResource) Configure(
+	_ context.Context,
+	req resource.ConfigureRequest,
+	resp *resource.ConfigureResponse,
+) {
+	if req.ProviderData == nil {
+		return
+	}
+	data := req.ProviderData.(*providerData)
+	r.client = data.client
+	r.defaultDriftMode = data.driftMode
+}
+
+// ModifyPlan plans an update when the ruleset changed outside of Terraform.
+func (r *organizationRulesetAllowedMergeMethodsResource) ModifyPlan(
+	ctx context.Context,
+	req resource.ModifyPlanRequest,
+	resp *resource.ModifyPlanResponse,
+) {
+	planRulesetChanged(ctx, req, resp, r.defaultDriftMode)
+}
+
+func (r *organizationRulesetAllowedMergeMethodsResource) Create(
+	ctx context.Context,
+	req resource.CreateRequest,
+	resp *resource.CreateResponse,
+) {
+	var plan organizationRulesetAllowedMergeMethodsResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	previous, err := r.upsert(ctx, &plan)
+	if err != nil {
+		resp.Diagnostics.AddError(rulesetWriteErrorDiagnostic("Error creating ruleset", err))
+		return
+	}
+
+	plan.ID = types.StringValue(fmt.Sprintf("%s:%s", plan.Organization.ValueString(), plan.RulesetID.ValueString()))
+
+	resp.Diagnostics.Append(setPreviousMergeMethods(ctx, resp.Private, previous)...)
+	resp.Diagnostics.Append(setAppliedFingerprint(ctx, resp.Private, plan.RulesetFingerprint)...)
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *organizationRulesetAllowedMergeMethodsResource) Read(
+	ctx context.Context,
+	req resource.ReadRequest,
+	resp *resource.ReadResponse,
+) {
+	var state organizationRulesetAllowedMergeMethodsResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	imported := state.AllowedMergeMethods.IsNull()
+	result, diags := r.refresh(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if result.Gone {
+		resp.Diagnostics.AddWarning(
+			"Ruleset no longer exists",
+			fmt.Sprintf("Ruleset %s of organization %s was not found, so the resource is removed from state.",
+				state.RulesetID.ValueString(), state.Organization.ValueString()),
+		)
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	if imported {
+		resp.Diagnostics.Append(setPreviousMergeMethods(ctx, resp.Private, extractMethodsFromSet(state.AllowedMergeMethods))...)
+	}
+
+	applied, diags := getAppliedFingerprint(ctx, req.Private)
+	resp.Diagnostics.Append(diags...)
+	if result.Restored || applied == "" {
+		resp.Diagnostics.Append(setAppliedFingerprint(ctx, resp.Private, state.RulesetFingerprint)...)
+	}
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// refresh updates state from GitHub and handles drift of the merge methods
+// according to the drift mode.
+func (r *organizationRulesetAllowedMergeMethodsResource) refresh(
+	ctx context.Context,
+	state *organizationRulesetAllowedMergeMethodsResourceModel,
+) (mergeMethodsRefresh, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	key, err := organizationRulesetKey(state.Organization, state.RulesetID)
+	if err != nil {
+		diags.AddError("Invalid ruleset ID", err.Error())
+		return mergeMethodsRefresh{}, diags
+	}
+
+	_, result, diags := refreshMergeMethods(
+		ctx, r.client, key, state.AllowedMergeMethods, resolveDriftMode(state.DriftMode, r.defaultDriftMode),
+	)
+	if diags.HasError() || result.Gone {
+		return result, diags
+	}
+	state.AllowedMergeMethods = result.AllowedMergeMethods
+	state.RulesetFingerprint = result.RulesetFingerprint
+
+	return result, diags
+}
+
+func (r *organizationRulesetAllowedMergeMethodsResource) Update(
+	ctx context.Context,
+	req resource.UpdateRequest,
+	resp *resource.UpdateResponse,
+) {
+	var plan organizationRulesetAllowedMergeMethodsResourceModel
+	diags := req.Plan.Get(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	_, err := r.upsert(ctx, &plan)
+	if err != nil {
+		resp.Diagnostics.AddError(rulesetWriteErrorDiagnostic("Error updating ruleset", err))
+		return
+	}
+
+	resp.Diagnostics.Append(setAppliedFingerprint(ctx, resp.Private, plan.RulesetFingerprint)...)
+	diags = resp.State.Set(ctx, plan)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (r *organizationRulesetAllowedMergeMethodsResource) Delete(
+	ctx context.Context,
+	req resource.DeleteRequest,
+	resp *resource.DeleteResponse,
+) {
+	var state organizationRulesetAllowedMergeMethodsResourceModel
+	diags := req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	key, err := organizationRulesetKey(state.Organization, state.RulesetID)
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid ruleset ID", err.Error())
+		return
+	}
+
+	resp.Diagnostics.Append(destroyMergeMethods(ctx, r.client, key, state.OnDestroy, req.Private)...)
+}
+
+func (r *organizationRulesetAllowedMergeMethodsResource) ImportState(
+	ctx context.Context,
+	req resource.ImportStateRequest,
+	resp *resource.ImportStateResponse,
+) {
+	org, rulesetID, ok := strings.Cut(req.ID, ":")
+	if _, err := parseID(rulesetID); !ok || org == "" || err != nil {
+		resp.Diagnostics.AddError(
+			"Unexpected Import Identifier",
+			fmt.Sprintf("Expected import identifier with format: org:ruleset_id. Got: %q", req.ID),
+		)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("organization"), org)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("ruleset_id"), rulesetID)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), req.ID)...)
+}
+
+// upsert writes the planned merge methods and returns the methods the ruleset
+// allowed before.
+func (r *organizationRulesetAllowedMergeMethodsResource) upsert(
+	ctx context.Context,
+	plan *organizationRulesetAllowedMergeMethodsResourceModel,
+) ([]string, error) {
+	key, err := organizationRulesetKey(plan.Organization, plan.RulesetID)
+	if err != nil {
+		return nil, err
+	}
+
+	previous, fingerprint, err := writeMergeMethods(ctx, r.client, key, extractMethodsFromSet(plan.AllowedMergeMethods))
+	if err != nil {
+		return nil, err
+	}
+	plan.RulesetFingerprint = fingerprint
+	return previous, nil
+}
+
+// organizationRulesetKey returns the key of an organization ruleset.
+func organizationRulesetKey(org, rulesetID types.String) (githubclient.RulesetKey, error) {
+	id, err := parseID(rulesetID.ValueString())
+	if err != nil {
+		return githubclient.RulesetKey{}, err
+	}
+	return githubclient.RulesetKey{Owner: org.ValueString(), ID: id}, nil
+}
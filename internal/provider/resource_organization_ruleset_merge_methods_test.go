@@ -0,0 +1,132 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-github/v74/github"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/knowledge-work/terraform-provider-kw-github/internal/githubclient"
+)
+
+func TestOrganizationRulesetAllowedMergeMethods(t *testing.T) {
+	var putBody struct {
+		Rules []struct {
+			Type       string `json:"type"`
+			Parameters struct {
+				AllowedMergeMethods []string `json:"allowed_merge_methods"`
+			} `json:"parameters"`
+		} `json:"rules"`
+	}
+	var puts int
+	methods := `["merge", "squash", "rebase"]`
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/orgs/my-org/rulesets/123", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.RawQuery != "" {
+			t.Errorf("Expected no query for an organization ruleset, got %q", r.URL.RawQuery)
+		}
+		if r.Method == "PUT" {
+			puts++
+			if err := json.NewDecoder(r.Body).Decode(&putBody); err != nil {
+				t.Errorf("failed to decode PUT body: %v", err)
+			}
+		}
+		fmt.Fprintf(w, `{"id": 123, "source_type": "Organization", "rules": [{"type": "pull_request", "parameters": {"allowed_merge_methods": %s}}]}`, methods)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := github.NewClient(nil)
+	client.BaseURL, _ = client.BaseURL.Parse(server.URL + "/")
+
+	r := &organizationRulesetAllowedMergeMethodsResource{
+		client: &githubclient.Client{Client: client, Owner: "owner"},
+	}
+	model := &organizationRulesetAllowedMergeMethodsResourceModel{
+		Organization:        types.StringValue("my-org"),
+		RulesetID:           types.StringValue("123"),
+		AllowedMergeMethods: convertToSet([]string{"squash"}),
+	}
+
+	t.Run("upsert", func(t *testing.T) {
+		if _, err := r.upsert(context.Background(), model); err != nil {
+			t.Fatalf("upsert failed: %v", err)
+		}
+		if puts != 1 {
+			t.Fatalf("Expected 1 PUT, got %d", puts)
+		}
+		if len(putBody.Rules) != 1 || !methodsEqual(putBody.Rules[0].Parameters.AllowedMergeMethods, []string{"squash"}) {
+			t.Errorf("Expected squash to be written, got %+v", putBody.Rules)
+		}
+		if model.RulesetFingerprint.IsNull() {
+			t.Error("Expected ruleset_fingerprint to be set")
+		}
+	})
+
+	t.Run("refresh restores reset methods", func(t *testing.T) {
+		puts = 0
+		result, diags := r.refresh(context.Background(), model)
+		if diags.HasError() {
+			t.Fatalf("refresh failed: %v", diags)
+		}
+		if !result.Restored || puts != 1 {
+			t.Errorf("Expected the merge methods to be restored, got restored %v with %d PUTs", result.Restored, puts)
+		}
+		if got := extractMethodsFromSet(model.AllowedMergeMethods); !methodsEqual(got, []string{"squash"}) {
+			t.Errorf("Expected squash in state, got %v", got)
+		}
+	})
+
+	t.Run("refresh without drift", func(t *testing.T) {
+		puts = 0
+		methods = `["squash"]`
+		result, diags := r.refresh(context.Background(), model)
+		if diags.HasError() {
+			t.Fatalf("refresh failed: %v", diags)
+		}
+		if result.Restored || puts != 0 {
+			t.Errorf("Expected no write, got restored %v with %d PUTs", result.Restored, puts)
+		}
+	})
+}
+
+func TestOrganizationRulesetAllowedMergeMethodsImportState(t *testing.T) {
+	tests := []struct {
+		id         string
+		wantErrors bool
+	}{
+		{id: "my-org:123"},
+		{id: "my-org:main", wantErrors: true},
+		{id: "my-org:", wantErrors: true},
+		{id: "my-org:+123", wantErrors: true},
+		{id: ":123", wantErrors: true},
+		{id: "my-org", wantErrors: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.id, func(t *testing.T) {
+			ctx := context.Background()
+			r := &organizationRulesetAllowedMergeMethodsResource{}
+
+			var schemaResp resource.SchemaResponse
+			r.Schema(ctx, resource.SchemaRequest{}, &schemaResp)
+			resp := &resource.ImportStateResponse{State: tfsdk.State{
+				Schema: schemaResp.Schema,
+				Raw:    tftypes.NewValue(schemaResp.Schema.Type().TerraformType(ctx), nil),
+			}}
+			r.ImportState(ctx, resource.ImportStateRequest{ID: test.id}, resp)
+
+			if resp.Diagnostics.HasError() != test.wantErrors {
+				t.Errorf("Expected errors %v, got %v", test.wantErrors, resp.Diagnostics)
+			}
+		})
+	}
+}
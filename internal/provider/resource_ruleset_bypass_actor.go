@@ -0,0 +1,513 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/knowledge-work/terraform-provider-kw-github/internal/githubclient"
+)
+
+// bypassActorTypes are the actor types a ruleset bypass actor can have.
+var bypassActorTypes = []string{"Integration", "OrganizationAdmin", "RepositoryRole", "Team", "DeployKey"}
+
+// bypassActorTypesWithoutID are the actor types that have no actor ID; there
+// is at most one bypass actor of each of them.
+var bypassActorTypesWithoutID = []string{"OrganizationAdmin", "DeployKey"}
+
+var bypassModes = []string{"always", "pull_request", "exempt"}
+
+func NewRulesetBypassActorResource() resource.Resource {
+	return &rulesetBypassActorResource{}
+}
+
+type rulesetBypassActorResource struct {
+	client           *githubclient.Client
+	defaultDriftMode string
+}
+
+type rulesetBypassActorResourceModel struct {
+	rulesetTargetModel
+	ActorType  types.String `tfsdk:"actor_type"`
+	ActorID    types.Int64  `tfsdk:"actor_id"`
+	BypassMode types.String `tfsdk:"bypass_mode"`
+	DriftMode  types.String `tfsdk:"drift_mode"`
+}
+
+// rulesetBypassActor is the part of a bypass_actors entry the resource
+// manages. Entries are otherwise kept as raw JSON.
+type rulesetBypassActor struct {
+	ActorID    *int64 `json:"actor_id"`
+	ActorType  string `json:"actor_type"`
+	BypassMode string `json:"bypass_mode,omitempty"`
+}
+
+func (r *rulesetBypassActorResource) Metadata(
+	_ context.Context,
+	req resource.MetadataRequest,
+	resp *resource.MetadataResponse,
+) {
+	resp.TypeName = req.ProviderTypeName + "_ruleset_bypass_actor"
+}
+
+func (r *rulesetBypassActorResource) Schema(
+	_ context.Context,
+	_ resource.SchemaRequest,
+	resp *resource.SchemaResponse,
+) {
+	resp.Schema = schema.Schema{
+		Description: "Manages a single bypass actor of a repository ruleset. Other bypass actors are left untouched. Creating the resource fails if the ruleset already has the actor; import it instead.",
+		Attributes: rulesetTargetAttributes(map[string]schema.Attribute{
+			"actor_type": schema.StringAttribute{
+				Required:    true,
+				Description: "The type of the actor. Valid values are: 'Integration', 'OrganizationAdmin', 'RepositoryRole', 'Team', 'DeployKey'.",
+				Validators: []validator.String{
+					stringvalidator.OneOf(bypassActorTypes...),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"actor_id": schema.Int64Attribute{
+				Optional:    true,
+				Description: "The ID of the actor, e.g. the app ID of an Integration or the ID of a Team. Required unless actor_type is 'OrganizationAdmin' or 'DeployKey'.",
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.RequiresReplace(),
+				},
+			},
+			"bypass_mode": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString("always"),
+				Description: "When the actor can bypass the ruleset. Valid values are: 'always', 'pull_request', 'exempt'. Defaults to 'always'.",
+				Validators: []validator.String{
+					stringvalidator.OneOf(bypassModes...),
+				},
+			},
+			"drift_mode": driftModeAttribute("the bypass actor was removed or changed"),
+		}),
+	}
+}
+
+func (r *rulesetBypassActorResource) ValidateConfig(
+	ctx context.Context,
+	req resource.ValidateConfigRequest,
+	resp *resource.ValidateConfigResponse,
+) {
+	var config rulesetBypassActorResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() || config.ActorType.IsUnknown() || config.ActorID.IsUnknown() {
+		return
+	}
+
+	withoutID := slices.Contains(bypassActorTypesWithoutID, config.ActorType.ValueString())
+	if !withoutID && config.ActorID.IsNull() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("actor_id"),
+			"Missing actor ID",
+			fmt.Sprintf("actor_id is required for actor type %q.", config.ActorType.ValueString()),
+		)
+	}
+	if withoutID && !config.ActorID.IsNull() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("actor_id"),
+			"Unexpected actor ID",
+			fmt.Sprintf("Actor type %q has no actor ID; remove actor_id.", config.ActorType.ValueString()),
+		)
+	}
+}
+
+func (r *rulesetBypassActorResource) Configure(
+	_ context.Context,
+	req resource.ConfigureRequest,
+	resp *resource.ConfigureResponse,
+) {
+	if req.ProviderData == nil {
+		return
+	}
+	data := req.ProviderData.(*providerData)
+	r.client = data.client
+	r.defaultDriftMode = data.driftMode
+}
+
+func (r *rulesetBypassActorResource) Create(
+	ctx context.Context,
+	req resource.CreateRequest,
+	resp *resource.CreateResponse,
+) {
+	var plan rulesetBypassActorResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.upsert(ctx, &plan, addBypassActor)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+}
+
+func (r *rulesetBypassActorResource) Read(
+	ctx context.Context,
+	req resource.ReadRequest,
+	resp *resource.ReadResponse,
+) {
+	var state rulesetBypassActorResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	found, diags := r.refresh(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if !found {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// refresh updates state from GitHub and handles drift of the actor according
+// to the drift mode. It returns false if the ruleset is gone, or if the actor
+// was removed and the drift mode lets the plan add it again.
+func (r *rulesetBypassActorResource) refresh(
+	ctx context.Context,
+	state *rulesetBypassActorResourceModel,
+) (bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	key, err := state.key(r.client.Owner)
+	if err != nil {
+		diags.AddError("Invalid ruleset", err.Error())
+		return false, diags
+	}
+
+	ruleset, diags := getRulesetTarget(ctx, r.client, key)
+	if ruleset == nil {
+		return false, diags
+	}
+
+	imported := state.ID.IsNull()
+	state.ID = types.StringValue(state.resourceID(key))
+
+	desired := state.actor()
+	actual, err := findBypassActor(ruleset, desired)
+	if err != nil {
+		diags.AddError("Error reading a ruleset", err.Error())
+		return false, diags
+	}
+
+	switch {
+	case imported:
+		if actual == nil {
+			diags.AddError(
+				"Bypass actor not found",
+				fmt.Sprintf("Ruleset %s has no bypass actor %s.", key, desired),
+			)
+			return false, diags
+		}
+		state.BypassMode = types.StringValue(actual.BypassMode)
+	case actual == nil || actual.BypassMode != desired.BypassMode:
+		useActual, driftDiags := handleRuleDrift(
+			ctx, r.client, key, resolveDriftMode(state.DriftMode, r.defaultDriftMode),
+			func(ruleset *githubclient.Ruleset) error {
+				return setBypassActor(ruleset, desired)
+			},
+		)
+		diags.Append(driftDiags...)
+		if useActual {
+			if actual == nil {
+				// The actor was removed; let the plan add it again.
+				return false, diags
+			}
+			state.BypassMode = types.StringValue(actual.BypassMode)
+		}
+	}
+	return true, diags
+}
+
+func (r *rulesetBypassActorResource) Update(
+	ctx context.Context,
+	req resource.UpdateRequest,
+	resp *resource.UpdateResponse,
+) {
+	var plan rulesetBypassActorResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.upsert(ctx, &plan, setBypassActor)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+}
+
+// Delete removes the bypass actor from the ruleset.
+func (r *rulesetBypassActorResource) Delete(
+	ctx context.Context,
+	req resource.DeleteRequest,
+	resp *resource.DeleteResponse,
+) {
+	var state rulesetBypassActorResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.destroy(ctx, &state)...)
+}
+
+// destroy removes the actor from the ruleset, if the ruleset still exists.
+func (r *rulesetBypassActorResource) destroy(
+	ctx context.Context,
+	state *rulesetBypassActorResourceModel,
+) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	key, err := state.key(r.client.Owner)
+	if err != nil {
+		diags.AddError("Invalid ruleset", err.Error())
+		return diags
+	}
+
+	_, err = r.client.ModifyRuleset(ctx, key, func(ruleset *githubclient.Ruleset) error {
+		return removeBypassActor(ruleset, state.actor())
+	})
+	if isRulesetGone(err) {
+		// Nothing left to remove the actor from.
+		return diags
+	}
+	if err != nil {
+		diags.AddError(rulesetWriteErrorDiagnostic("Error removing a bypass actor", err))
+	}
+	return diags
+}
+
+// ImportState accepts [owner/]repo:ruleset_id:actor_type:actor_id, or
+// [owner/]repo:ruleset_id:actor_type for actor types without an ID.
+func (r *rulesetBypassActorResource) ImportState(
+	ctx context.Context,
+	req resource.ImportStateRequest,
+	resp *resource.ImportStateResponse,
+) {
+	extra := []string{"actor_type", "actor_id"}
+	if strings.Count(req.ID, ":") == 2 {
+		extra = extra[:1]
+	}
+
+	fields, ok := importRulesetTarget(ctx, req.ID, resp, extra...)
+	if !ok {
+		return
+	}
+
+	actorType := fields[0]
+	withoutID := slices.Contains(bypassActorTypesWithoutID, actorType)
+	if !slices.Contains(bypassActorTypes, actorType) || withoutID != (len(fields) == 1) {
+		resp.Diagnostics.AddError(
+			"Unexpected Import Identifier",
+			fmt.Sprintf("Expected import identifier with format: [owner/]repo:ruleset_id:actor_type:actor_id, "+
+				"or [owner/]repo:ruleset_id:actor_type for %s. Got: %q",
+				strings.Join(bypassActorTypesWithoutID, " and "), req.ID),
+		)
+		return
+	}
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("actor_type"), actorType)...)
+
+	if len(fields) == 2 {
+		actorID, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			resp.Diagnostics.AddError("Unexpected Import Identifier", fmt.Sprintf("Invalid actor ID %q: %v", fields[1], err))
+			return
+		}
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("actor_id"), actorID)...)
+	}
+}
+
+// upsert writes the bypass actor in plan with apply, which is addBypassActor
+// on create and setBypassActor on update.
+func (r *rulesetBypassActorResource) upsert(
+	ctx context.Context,
+	plan *rulesetBypassActorResourceModel,
+	apply func(ruleset *githubclient.Ruleset, actor rulesetBypassActor) error,
+) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	key, err := plan.key(r.client.Owner)
+	if err != nil {
+		diags.AddError("Invalid ruleset", err.Error())
+		return diags
+	}
+
+	actor := plan.actor()
+	_, err = r.client.ModifyRuleset(ctx, key, func(ruleset *githubclient.Ruleset) error {
+		return apply(ruleset, actor)
+	})
+	if errors.Is(err, errRulesetEntryExists) {
+		diags.AddError(entryExistsDiagnostic(key, "bypass actor "+actor.String(), plan.resourceID(key)))
+		return diags
+	}
+	if err != nil {
+		diags.AddError(rulesetWriteErrorDiagnostic("Error updating ruleset", err))
+		return diags
+	}
+
+	plan.ID = types.StringValue(plan.resourceID(key))
+	return diags
+}
+
+// actor returns the bypass actor described by the model.
+func (m *rulesetBypassActorResourceModel) actor() rulesetBypassActor {
+	actor := rulesetBypassActor{
+		ActorType:  m.ActorType.ValueString(),
+		BypassMode: m.BypassMode.ValueString(),
+	}
+	if !m.ActorID.IsNull() {
+		id := m.ActorID.ValueInt64()
+		actor.ActorID = &id
+	}
+	return actor
+}
+
+// resourceID returns owner/repo:ruleset_id:actor_type[:actor_id].
+func (m *rulesetBypassActorResourceModel) resourceID(key githubclient.RulesetKey) string {
+	if m.ActorID.IsNull() {
+		return rulesetTargetID(key, m.ActorType.ValueString())
+	}
+	return rulesetTargetID(key, m.ActorType.ValueString(), strconv.FormatInt(m.ActorID.ValueInt64(), 10))
+}
+
+func (a rulesetBypassActor) String() string {
+	if a.ActorID == nil {
+		return a.ActorType
+	}
+	return fmt.Sprintf("%s %d", a.ActorType, *a.ActorID)
+}
+
+// matches reports whether other is the same actor. The ID is ignored for
+// actor types without one, since GitHub reports e.g. OrganizationAdmin with
+// an ID of its own.
+func (a rulesetBypassActor) matches(other rulesetBypassActor) bool {
+	if a.ActorType != other.ActorType {
+		return false
+	}
+	if slices.Contains(bypassActorTypesWithoutID, a.ActorType) {
+		return true
+	}
+	return a.ActorID != nil && other.ActorID != nil && *a.ActorID == *other.ActorID
+}
+
+// bypassActors returns the ruleset's bypass actors as raw JSON, so that
+// fields this provider does not know are kept, along with their decoded form.
+func bypassActors(ruleset *githubclient.Ruleset) ([]json.RawMessage, []rulesetBypassActor, error) {
+	var raw []json.RawMessage
+	if _, err := ruleset.Field("bypass_actors", &raw); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode bypass actors: %w", err)
+	}
+
+	actors := make([]rulesetBypassActor, len(raw))
+	for i, entry := range raw {
+		if err := json.Unmarshal(entry, &actors[i]); err != nil {
+			return nil, nil, fmt.Errorf("failed to decode bypass actor: %w", err)
+		}
+	}
+	return raw, actors, nil
+}
+
+// findBypassActor returns the ruleset's entry for actor, or nil if there is
+// none.
+func findBypassActor(ruleset *githubclient.Ruleset, actor rulesetBypassActor) (*rulesetBypassActor, error) {
+	_, actors, err := bypassActors(ruleset)
+	if err != nil {
+		return nil, err
+	}
+	for _, existing := range actors {
+		if existing.matches(actor) {
+			return &existing, nil
+		}
+	}
+	return nil, nil
+}
+
+// addBypassActor adds actor to the ruleset's bypass actors. It returns
+// errRulesetEntryExists if the ruleset already has an entry for the actor.
+func addBypassActor(ruleset *githubclient.Ruleset, actor rulesetBypassActor) error {
+	existing, err := findBypassActor(ruleset, actor)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return errRulesetEntryExists
+	}
+	return setBypassActor(ruleset, actor)
+}
+
+// setBypassActor adds actor to the ruleset's bypass actors, or updates the
+// bypass mode of its existing entry.
+func setBypassActor(ruleset *githubclient.Ruleset, actor rulesetBypassActor) error {
+	raw, actors, err := bypassActors(ruleset)
+	if err != nil {
+		return err
+	}
+
+	for i, existing := range actors {
+		if !existing.matches(actor) {
+			continue
+		}
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(raw[i], &fields); err != nil {
+			return fmt.Errorf("failed to decode bypass actor: %w", err)
+		}
+		fields["bypass_mode"], _ = json.Marshal(actor.BypassMode)
+		if raw[i], err = json.Marshal(fields); err != nil {
+			return err
+		}
+		return ruleset.SetField("bypass_actors", raw)
+	}
+
+	entry, err := json.Marshal(actor)
+	if err != nil {
+		return err
+	}
+	return ruleset.SetField("bypass_actors", append(raw, entry))
+}
+
+// removeBypassActor removes actor from the ruleset's bypass actors. Other
+// entries are kept as they are.
+func removeBypassActor(ruleset *githubclient.Ruleset, actor rulesetBypassActor) error {
+	raw, actors, err := bypassActors(ruleset)
+	if err != nil {
+		return err
+	}
+
+	kept := make([]json.RawMessage, 0, len(raw))
+	for i, existing := range actors {
+		if !existing.matches(actor) {
+			kept = append(kept, raw[i])
+		}
+	}
+	return ruleset.SetField("bypass_actors", kept)
+}
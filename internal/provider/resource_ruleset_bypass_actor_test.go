@@ -0,0 +1,281 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/knowledge-work/terraform-provider-kw-github/internal/githubclient"
+)
+
+func bypassActorsJSON(t *testing.T, ruleset *githubclient.Ruleset) string {
+	t.Helper()
+	var raw json.RawMessage
+	if _, err := ruleset.Field("bypass_actors", &raw); err != nil {
+		t.Fatal(err)
+	}
+	return string(raw)
+}
+
+func int64Ptr(v int64) *int64 {
+	return &v
+}
+
+func TestSetBypassActor(t *testing.T) {
+	const existing = `{"bypass_actors": [{"actor_id": 1, "actor_type": "OrganizationAdmin", "bypass_mode": "always"}, {"actor_id": 5, "actor_type": "Team", "bypass_mode": "always", "future_field": true}]}`
+
+	tests := []struct {
+		name  string
+		actor rulesetBypassActor
+		want  string
+	}{
+		{
+			name:  "adds a new actor",
+			actor: rulesetBypassActor{ActorID: int64Ptr(7), ActorType: "Integration", BypassMode: "pull_request"},
+			want:  `[{"actor_id":1,"actor_type":"OrganizationAdmin","bypass_mode":"always"},{"actor_id":5,"actor_type":"Team","bypass_mode":"always","future_field":true},{"actor_id":7,"actor_type":"Integration","bypass_mode":"pull_request"}]`,
+		},
+		{
+			name:  "updates the mode of an existing actor",
+			actor: rulesetBypassActor{ActorID: int64Ptr(5), ActorType: "Team", BypassMode: "exempt"},
+			want:  `[{"actor_id":1,"actor_type":"OrganizationAdmin","bypass_mode":"always"},{"actor_id":5,"actor_type":"Team","bypass_mode":"exempt","future_field":true}]`,
+		},
+		{
+			name:  "matches actor types without an ID by type",
+			actor: rulesetBypassActor{ActorType: "OrganizationAdmin", BypassMode: "pull_request"},
+			want:  `[{"actor_id":1,"actor_type":"OrganizationAdmin","bypass_mode":"pull_request"},{"actor_id":5,"actor_type":"Team","bypass_mode":"always","future_field":true}]`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ruleset := decodeRuleset(t, existing)
+			if err := setBypassActor(ruleset, test.actor); err != nil {
+				t.Fatalf("setBypassActor failed: %v", err)
+			}
+			if got := bypassActorsJSON(t, ruleset); got != test.want {
+				t.Errorf("Expected %s, got %s", test.want, got)
+			}
+		})
+	}
+}
+
+func TestRemoveBypassActor(t *testing.T) {
+	ruleset := decodeRuleset(t, `{"bypass_actors": [{"actor_id": 5, "actor_type": "Team", "bypass_mode": "always"}, {"actor_id": 5, "actor_type": "Integration", "bypass_mode": "always"}]}`)
+
+	if err := removeBypassActor(ruleset, rulesetBypassActor{ActorID: int64Ptr(5), ActorType: "Team"}); err != nil {
+		t.Fatalf("removeBypassActor failed: %v", err)
+	}
+	want := `[{"actor_id":5,"actor_type":"Integration","bypass_mode":"always"}]`
+	if got := bypassActorsJSON(t, ruleset); got != want {
+		t.Errorf("Expected %s, got %s", want, got)
+	}
+
+	if err := removeBypassActor(ruleset, rulesetBypassActor{ActorID: int64Ptr(5), ActorType: "Integration"}); err != nil {
+		t.Fatalf("removeBypassActor failed: %v", err)
+	}
+	if got := bypassActorsJSON(t, ruleset); got != `[]` {
+		t.Errorf("Expected no bypass actors, got %s", got)
+	}
+}
+
+func TestBypassActorUpsert(t *testing.T) {
+	tests := []struct {
+		name      string
+		actors    string
+		wantError bool
+	}{
+		{name: "adds a new actor", actors: `[{"actor_id": 1, "actor_type": "OrganizationAdmin", "bypass_mode": "always"}]`},
+		{name: "rejects an existing actor", actors: `[{"actor_id": 5, "actor_type": "Team", "bypass_mode": "pull_request"}]`, wantError: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client, puts := newRulesetServer(t, `{"id": 123, "rules": [], "bypass_actors": `+test.actors+`}`)
+			r := &rulesetBypassActorResource{client: client}
+
+			plan := rulesetBypassActorResourceModel{
+				rulesetTargetModel: rulesetTargetModel{Repository: types.StringValue("repo"), RulesetID: types.StringValue("123")},
+				ActorType:          types.StringValue("Team"),
+				ActorID:            types.Int64Value(5),
+				BypassMode:         types.StringValue("always"),
+			}
+			diags := r.upsert(context.Background(), &plan, addBypassActor)
+
+			if diags.HasError() != test.wantError {
+				t.Fatalf("Expected error %v, got %v", test.wantError, diags)
+			}
+			if test.wantError {
+				if detail := diags.Errors()[0].Detail(); !strings.Contains(detail, `terraform import using the ID "owner/repo:123:Team:5"`) {
+					t.Errorf("Expected the error to point to terraform import, got %q", detail)
+				}
+				if len(*puts) != 0 {
+					t.Errorf("Expected no PUT, got %d", len(*puts))
+				}
+				return
+			}
+			if plan.ID.ValueString() != "owner/repo:123:Team:5" {
+				t.Errorf("Unexpected ID %s", plan.ID)
+			}
+			if len(*puts) != 1 {
+				t.Fatalf("Expected 1 PUT, got %d", len(*puts))
+			}
+		})
+	}
+}
+
+func TestBypassActorReadAfterImport(t *testing.T) {
+	client, puts := newRulesetServer(t, `{"id": 123, "rules": [], "bypass_actors": [{"actor_id": 5, "actor_type": "Team", "bypass_mode": "pull_request"}]}`)
+	r := &rulesetBypassActorResource{client: client}
+
+	state := rulesetBypassActorResourceModel{
+		rulesetTargetModel: rulesetTargetModel{Repository: types.StringValue("repo"), RulesetID: types.StringValue("123")},
+		ActorType:          types.StringValue("Team"),
+		ActorID:            types.Int64Value(5),
+	}
+	found, diags := r.refresh(context.Background(), &state)
+	if diags.HasError() {
+		t.Fatalf("refresh failed: %v", diags)
+	}
+	if !found {
+		t.Fatal("Expected the resource to be kept")
+	}
+	if state.BypassMode.ValueString() != "pull_request" {
+		t.Errorf("Expected the bypass mode to be adopted, got %s", state.BypassMode)
+	}
+	if len(*puts) != 0 {
+		t.Errorf("Expected no PUT, got %d", len(*puts))
+	}
+}
+
+func TestBypassActorReadDrift(t *testing.T) {
+	tests := []struct {
+		name      string
+		driftMode string
+		actors    string
+		wantMode  string
+		wantKept  bool
+		wantPut   bool
+	}{
+		{"restore removed actor", driftModeRestore, `[]`, "always", true, true},
+		{"report removed actor", driftModeReport, `[]`, "", false, false},
+		{"report changed mode", driftModeReport, `[{"actor_id": 5, "actor_type": "Team", "bypass_mode": "pull_request"}]`, "pull_request", true, false},
+		{"ignore changed mode", driftModeIgnore, `[{"actor_id": 5, "actor_type": "Team", "bypass_mode": "pull_request"}]`, "always", true, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client, puts := newRulesetServer(t, `{"id": 123, "rules": [], "bypass_actors": `+test.actors+`}`)
+			r := &rulesetBypassActorResource{client: client, defaultDriftMode: test.driftMode}
+
+			state := rulesetBypassActorResourceModel{
+				rulesetTargetModel: rulesetTargetModel{
+					Repository: types.StringValue("repo"),
+					RulesetID:  types.StringValue("123"),
+					ID:         types.StringValue("owner/repo:123:Team:5"),
+				},
+				ActorType:  types.StringValue("Team"),
+				ActorID:    types.Int64Value(5),
+				BypassMode: types.StringValue("always"),
+			}
+
+			found, diags := r.refresh(context.Background(), &state)
+			if diags.HasError() {
+				t.Fatalf("refresh failed: %v", diags)
+			}
+			if found != test.wantKept {
+				t.Fatalf("Expected the resource to be kept %v, got %v", test.wantKept, found)
+			}
+			if found && state.BypassMode.ValueString() != test.wantMode {
+				t.Errorf("Expected bypass_mode %q, got %s", test.wantMode, state.BypassMode)
+			}
+			if (len(*puts) > 0) != test.wantPut {
+				t.Errorf("Expected PUT %v, got %d PUTs", test.wantPut, len(*puts))
+			}
+		})
+	}
+}
+
+func TestBypassActorDestroy(t *testing.T) {
+	client, puts := newRulesetServer(t, `{"id": 123, "rules": [], "bypass_actors": [{"actor_id": 1, "actor_type": "OrganizationAdmin", "bypass_mode": "always"}, {"actor_id": 5, "actor_type": "Team", "bypass_mode": "always"}]}`)
+	r := &rulesetBypassActorResource{client: client}
+
+	state := rulesetBypassActorResourceModel{
+		rulesetTargetModel: rulesetTargetModel{
+			Repository: types.StringValue("repo"),
+			RulesetID:  types.StringValue("123"),
+			ID:         types.StringValue("owner/repo:123:Team:5"),
+		},
+		ActorType:  types.StringValue("Team"),
+		ActorID:    types.Int64Value(5),
+		BypassMode: types.StringValue("always"),
+	}
+	if diags := r.destroy(context.Background(), &state); diags.HasError() {
+		t.Fatalf("destroy failed: %v", diags)
+	}
+
+	if len(*puts) != 1 {
+		t.Fatalf("Expected 1 PUT, got %d", len(*puts))
+	}
+	var body struct {
+		BypassActors json.RawMessage `json:"bypass_actors"`
+	}
+	if err := json.Unmarshal((*puts)[0], &body); err != nil {
+		t.Fatal(err)
+	}
+	want := `[{"actor_id":1,"actor_type":"OrganizationAdmin","bypass_mode":"always"}]`
+	if string(body.BypassActors) != want {
+		t.Errorf("Expected %s, got %s", want, body.BypassActors)
+	}
+}
+
+func TestBypassActorImportState(t *testing.T) {
+	tests := []struct {
+		id         string
+		wantType   string
+		wantActor  int64
+		wantErrors bool
+	}{
+		{id: "repo:123:Team:5", wantType: "Team", wantActor: 5},
+		{id: "other/repo:123:DeployKey", wantType: "DeployKey"},
+		{id: "repo:123:Team", wantErrors: true},
+		{id: "repo:123:OrganizationAdmin:1", wantErrors: true},
+		{id: "repo:123:Team:abc", wantErrors: true},
+		{id: "repo:123:Robot:5", wantErrors: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.id, func(t *testing.T) {
+			ctx := context.Background()
+			r := &rulesetBypassActorResource{}
+
+			var schemaResp resource.SchemaResponse
+			r.Schema(ctx, resource.SchemaRequest{}, &schemaResp)
+			resp := &resource.ImportStateResponse{State: tfsdk.State{
+				Schema: schemaResp.Schema,
+				Raw:    tftypes.NewValue(schemaResp.Schema.Type().TerraformType(ctx), nil),
+			}}
+			r.ImportState(ctx, resource.ImportStateRequest{ID: test.id}, resp)
+
+			if resp.Diagnostics.HasError() != test.wantErrors {
+				t.Fatalf("Expected errors %v, got %v", test.wantErrors, resp.Diagnostics)
+			}
+			if test.wantErrors {
+				return
+			}
+
+			var got rulesetBypassActorResourceModel
+			resp.Diagnostics.Append(resp.State.Get(ctx, &got)...)
+			if resp.Diagnostics.HasError() {
+				t.Fatal(resp.Diagnostics)
+			}
+			if got.ActorType.ValueString() != test.wantType || got.ActorID.ValueInt64() != test.wantActor {
+				t.Errorf("Expected %s %d, got %s %s", test.wantType, test.wantActor, got.ActorType, got.ActorID)
+			}
+		})
+	}
+}
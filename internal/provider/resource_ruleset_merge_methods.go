@@ -2,15 +2,24 @@ package provider
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/google/go-github/v74/github"
+	"github.com/hashicorp/terraform-plugin-framework-validators/setvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/knowledge-work/terraform-provider-kw-github/internal/githubclient"
 )
@@ -20,14 +29,20 @@ func NewRulesetAllowedMergeMethodsResource() resource.Resource {
 }
 
 type rulesetAllowedMergeMethodsResource struct {
-	client *githubclient.Client
+	client           *githubclient.Client
+	defaultDriftMode string
 }
 
 type rulesetAllowedMergeMethodsResourceModel struct {
+	Owner               types.String `tfsdk:"owner"`
 	Repository          types.String `tfsdk:"repository"`
 	RulesetID           types.String `tfsdk:"ruleset_id"`
+	RulesetName         types.String `tfsdk:"ruleset_name"`
 	AllowedMergeMethods types.Set    `tfsdk:"allowed_merge_methods"`
 	ForceUpdate         types.String `tfsdk:"force_update"`
+	DriftMode           types.String `tfsdk:"drift_mode"`
+	OnDestroy           types.String `tfsdk:"on_destroy"`
+	RulesetFingerprint  types.String `tfsdk:"ruleset_fingerprint"`
 	ID                  types.String `tfsdk:"id"`
 }
 
@@ -46,23 +61,69 @@ func (r *rulesetAllowedMergeMethodsResource) Schema(
 ) {
 	resp.Schema = schema.Schema{
 		Description: "Manages allowed merge methods for a GitHub repository ruleset.",
+		Version:     1,
 		Attributes: map[string]schema.Attribute{
+			"owner": schema.StringAttribute{
+				Optional:    true,
+				Description: "The owner of the repository. Defaults to the owner in repository, or the provider's owner.",
+				PlanModifiers: []planmodifier.String{
+					requiresReplaceIfRepositoryChanged(),
+				},
+			},
 			"repository": schema.StringAttribute{
 				Required:    true,
-				Description: "The name of the repository (e.g., 'repo-name').",
+				Description: "The name of the repository (e.g., 'repo-name'), or 'owner/repo-name' to manage a repository of another owner.",
+				PlanModifiers: []planmodifier.String{
+					requiresReplaceIfRepositoryChanged(),
+				},
 			},
 			"ruleset_id": schema.StringAttribute{
-				Required:    true,
-				Description: "The ID of the ruleset to manage.",
+				Optional:    true,
+				Computed:    true,
+				Description: "The ID of the ruleset to manage. Exactly one of ruleset_id and ruleset_name must be set; when ruleset_name is used, this is the resolved ID.",
+				Validators: []validator.String{
+					stringvalidator.ExactlyOneOf(path.MatchRoot("ruleset_name")),
+				},
+				PlanModifiers: []planmodifier.String{
+					rulesetIDPlanModifier{},
+					stringplanmodifier.RequiresReplaceIf(
+						func(_ context.Context, req planmodifier.StringRequest, resp *stringplanmodifier.RequiresReplaceIfFuncResponse) {
+							// An unknown ID is resolved from ruleset_name in ModifyPlan.
+							resp.RequiresReplace = !req.PlanValue.IsUnknown()
+						},
+						"Selecting another ruleset replaces the resource.",
+						"Selecting another ruleset replaces the resource.",
+					),
+				},
+			},
+			"ruleset_name": schema.StringAttribute{
+				Optional:    true,
+				Description: "The name of the ruleset to manage, as an alternative to ruleset_id. It must match exactly one ruleset defined on the repository.",
 			},
 			"allowed_merge_methods": schema.SetAttribute{
 				ElementType: types.StringType,
 				Required:    true,
 				Description: "Set of allowed merge methods. Valid values are: 'merge', 'squash', 'rebase'.",
+				Validators: []validator.Set{
+					setvalidator.SizeAtLeast(1),
+					setvalidator.ValueStringsAre(stringvalidator.OneOf(allMergeMethods...)),
+				},
 			},
 			"force_update": schema.StringAttribute{
 				Optional:    true,
-				Description: "Timestamp to force update when dependent resources change. Set this to a new value (e.g., timestamp) when you want to force an update.",
+				Description: "Arbitrary value to force an update when dependent resources change in the same apply. Changes made to the ruleset outside of Terraform are detected through ruleset_fingerprint without it.",
+			},
+			"drift_mode": driftModeAttribute("the merge methods were changed"),
+			"on_destroy": schema.StringAttribute{
+				Optional:    true,
+				Description: "What to do with the merge methods when the resource is destroyed. 'restore_previous' (default) restores the methods the ruleset allowed before Terraform managed them, 'allow_all' allows merge, squash and rebase, 'leave_as_is' keeps the current methods.",
+				Validators: []validator.String{
+					stringvalidator.OneOf(onDestroyModes...),
+				},
+			},
+			"ruleset_fingerprint": schema.StringAttribute{
+				Computed:    true,
+				Description: "Hash of the ruleset's pull_request rule, excluding the allowed merge methods and the parameters kwgithub_ruleset_pull_request_overlay manages. In restore drift mode, an update is planned when it changes outside of Terraform.",
 			},
 			"id": schema.StringAttribute{
 				Computed: true,
@@ -82,7 +143,95 @@ func (r *rulesetAllowedMergeMethodsResource) Configure(
 	if req.ProviderData == nil {
 		return
 	}
-	r.client = req.ProviderData.(*githubclient.Client)
+	data := req.ProviderData.(*providerData)
+	r.client = data.client
+	r.defaultDriftMode = data.driftMode
+}
+
+// ModifyPlan plans an update when the ruleset changed outside of Terraform,
+// and warns when the planned merge methods include one that the repository
+// itself disables, which leaves pull requests that can only be merged that
+// way unmergeable.
+func (r *rulesetAllowedMergeMethodsResource) ModifyPlan(
+	ctx context.Context,
+	req resource.ModifyPlanRequest,
+	resp *resource.ModifyPlanResponse,
+) {
+	// Nothing to check on destroy or before the provider is configured.
+	if req.Plan.Raw.IsNull() || r.client == nil {
+		return
+	}
+
+	planRulesetChanged(ctx, req, resp, r.defaultDriftMode)
+
+	var plan rulesetAllowedMergeMethodsResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if plan.Owner.IsUnknown() || plan.Repository.IsUnknown() {
+		return
+	}
+
+	owner, repo, err := splitRepository(plan.Owner, plan.Repository, r.client.Owner)
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(path.Root("repository"), "Invalid repository", err.Error())
+		return
+	}
+
+	if !req.State.Raw.IsNull() && plan.RulesetID.IsUnknown() && !plan.RulesetName.IsUnknown() {
+		r.planRulesetName(ctx, req, resp, owner, repo, plan.RulesetName.ValueString())
+	}
+	if plan.AllowedMergeMethods.IsUnknown() {
+		return
+	}
+
+	repository, _, err := r.client.Repositories.Get(ctx, owner, repo)
+	if err != nil {
+		// The repository is checked again when the ruleset is written.
+		return
+	}
+
+	methods := extractMethodsFromSet(plan.AllowedMergeMethods)
+	sort.Strings(methods)
+	disabled := disabledMergeMethods(repository, methods)
+	if len(disabled) == 0 {
+		return
+	}
+
+	detail := fmt.Sprintf("The ruleset allows %s, but repository %s disables %s in its settings. ",
+		strings.Join(methods, ", "), owner+"/"+repo, strings.Join(disabled, ", "))
+	if len(disabled) == len(methods) {
+		detail += "No allowed merge method is enabled, so pull requests targeting the ruleset's branches cannot be merged. "
+	}
+	detail += "Enable the methods in the repository settings (allow_merge_commit, allow_squash_merge, allow_rebase_merge) " +
+		"or remove them from allowed_merge_methods."
+	resp.Diagnostics.AddAttributeWarning(path.Root("allowed_merge_methods"), "Merge method disabled on the repository", detail)
+}
+
+// planRulesetName resolves a changed ruleset_name during plan. Selecting
+// another ruleset replaces the resource, so the merge methods of the old one
+// are restored by Delete and the previous methods of the new one are
+// recorded by Create. If the name cannot be resolved, the error is reported
+// by the update.
+func (r *rulesetAllowedMergeMethodsResource) planRulesetName(
+	ctx context.Context,
+	req resource.ModifyPlanRequest,
+	resp *resource.ModifyPlanResponse,
+	owner, repo, name string,
+) {
+	id, err := r.client.FindRulesetID(ctx, owner, repo, name)
+	if err != nil {
+		return
+	}
+
+	var stateID types.String
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("ruleset_id"), &stateID)...)
+	planned := types.StringValue(fmt.Sprintf("%d", id))
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("ruleset_id"), planned)...)
+	if !planned.Equal(stateID) {
+		resp.RequiresReplace = append(resp.RequiresReplace, path.Root("ruleset_id"))
+	}
 }
 
 func (r *rulesetAllowedMergeMethodsResource) Create(
@@ -97,14 +246,16 @@ func (r *rulesetAllowedMergeMethodsResource) Create(
 		return
 	}
 
-	err := r.upsert(ctx, &plan)
+	previous, err := r.upsert(ctx, &plan)
 	if err != nil {
-		resp.Diagnostics.AddError("Error creating ruleset", err.Error())
+		resp.Diagnostics.AddError(rulesetWriteErrorDiagnostic("Error creating ruleset", err))
 		return
 	}
 
-	plan.ID = types.StringValue(fmt.Sprintf("%s:%s", plan.Repository.ValueString(), plan.RulesetID.ValueString()))
+	plan.ID = types.StringValue(r.resourceID(&plan))
 
+	resp.Diagnostics.Append(setPreviousMergeMethods(ctx, resp.Private, previous)...)
+	resp.Diagnostics.Append(setAppliedFingerprint(ctx, resp.Private, plan.RulesetFingerprint)...)
 	diags = resp.State.Set(ctx, plan)
 	resp.Diagnostics.Append(diags...)
 }
@@ -121,48 +272,82 @@ func (r *rulesetAllowedMergeMethodsResource) Read(
 		return
 	}
 
-	owner := r.client.Owner
-	repo := state.Repository.ValueString()
+	imported := state.AllowedMergeMethods.IsNull()
+	result, diags := r.refresh(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if result.Gone {
+		resp.Diagnostics.AddWarning(
+			"Ruleset no longer exists",
+			fmt.Sprintf("Ruleset %s of repository %s was not found. It or the repository was deleted, archived or transferred, "+
+				"so the resource is removed from state.", state.RulesetID.ValueString(), state.Repository.ValueString()),
+		)
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	if imported {
+		resp.Diagnostics.Append(setPreviousMergeMethods(ctx, resp.Private, extractMethodsFromSet(state.AllowedMergeMethods))...)
+	}
 
-	rulesetID, err := parseID(state.RulesetID.ValueString())
+	// Record the fingerprint after our own restore, and adopt rulesets that
+	// were imported or created before fingerprints were recorded.
+	applied, diags := getAppliedFingerprint(ctx, req.Private)
+	resp.Diagnostics.Append(diags...)
+	if result.Restored || applied == "" {
+		resp.Diagnostics.Append(setAppliedFingerprint(ctx, resp.Private, state.RulesetFingerprint)...)
+	}
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+// refresh updates state from GitHub and handles drift of the merge methods
+// according to the drift mode.
+func (r *rulesetAllowedMergeMethodsResource) refresh(
+	ctx context.Context,
+	state *rulesetAllowedMergeMethodsResourceModel,
+) (mergeMethodsRefresh, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	owner, repo, err := splitRepository(state.Owner, state.Repository, r.client.Owner)
 	if err != nil {
-		resp.Diagnostics.AddError("Invalid ruleset ID", err.Error())
-		return
+		diags.AddError("Invalid repository", err.Error())
+		return mergeMethodsRefresh{}, diags
 	}
 
-	ruleset, _, err := r.client.Repositories.GetRuleset(ctx, owner, repo, rulesetID, true)
+	rulesetID, err := parseID(state.RulesetID.ValueString())
 	if err != nil {
-		resp.Diagnostics.AddError("Error reading a ruleset", err.Error())
-		return
+		diags.AddError("Invalid ruleset ID", err.Error())
+		return mergeMethodsRefresh{}, diags
 	}
 
-	var currentMethods []string
-	if ruleset.Rules != nil && ruleset.Rules.PullRequest != nil {
-		for _, method := range ruleset.Rules.PullRequest.AllowedMergeMethods {
-			currentMethods = append(currentMethods, string(method))
-		}
+	key := githubclient.RulesetKey{Owner: owner, Repo: repo, ID: rulesetID}
+	ruleset, result, diags := refreshMergeMethods(
+		ctx, r.client, key, state.AllowedMergeMethods, resolveDriftMode(state.DriftMode, r.defaultDriftMode),
+	)
+	if diags.HasError() || result.Gone {
+		return result, diags
 	}
+	state.AllowedMergeMethods = result.AllowedMergeMethods
+	state.RulesetFingerprint = result.RulesetFingerprint
 
-	// Check if current methods differ from expected methods
-	expectedMethods := extractMethodsFromSet(state.AllowedMergeMethods)
-	if !methodsEqual(currentMethods, expectedMethods) {
-		// Methods have been reset by GitHub, restore them
-		err = r.restoreMergeMethods(ctx, repo, rulesetID, expectedMethods)
-		if err != nil {
-			resp.Diagnostics.AddWarning(
-				"Merge methods were reset",
-				fmt.Sprintf("GitHub reset the merge methods, attempted to restore but failed: %v", err),
-			)
-		} else {
-			// Successfully restored, use expected methods
-			currentMethods = expectedMethods
+	// Track renames so the plan resolves ruleset_name again.
+	if !state.RulesetName.IsNull() {
+		var name string
+		if _, err := ruleset.Field("name", &name); err != nil {
+			diags.AddError("Error reading a ruleset", err.Error())
+			return result, diags
 		}
+		state.RulesetName = types.StringValue(name)
 	}
 
-	state.AllowedMergeMethods = convertToSet(currentMethods)
+	// Keep the ID in line with owner and repository changed by an update.
+	state.ID = types.StringValue(r.resourceID(state))
 
-	diags = resp.State.Set(ctx, &state)
-	resp.Diagnostics.Append(diags...)
+	return result, diags
 }
 
 func (r *rulesetAllowedMergeMethodsResource) Update(
@@ -177,12 +362,13 @@ func (r *rulesetAllowedMergeMethodsResource) Update(
 		return
 	}
 
-	err := r.upsert(ctx, &plan)
+	_, err := r.upsert(ctx, &plan)
 	if err != nil {
-		resp.Diagnostics.AddError("Error updating ruleset", err.Error())
+		resp.Diagnostics.AddError(rulesetWriteErrorDiagnostic("Error updating ruleset", err))
 		return
 	}
 
+	resp.Diagnostics.Append(setAppliedFingerprint(ctx, resp.Private, plan.RulesetFingerprint)...)
 	diags = resp.State.Set(ctx, plan)
 	resp.Diagnostics.Append(diags...)
 }
@@ -199,41 +385,20 @@ func (r *rulesetAllowedMergeMethodsResource) Delete(
 		return
 	}
 
-	owner := r.client.Owner
-	repo := state.Repository.ValueString()
-
-	rulesetID, err := parseID(state.RulesetID.ValueString())
+	owner, repo, err := splitRepository(state.Owner, state.Repository, r.client.Owner)
 	if err != nil {
-		resp.Diagnostics.AddError("Invalid ruleset ID", err.Error())
+		resp.Diagnostics.AddError("Invalid repository", err.Error())
 		return
 	}
 
-	ruleset, _, err := r.client.Repositories.GetRuleset(ctx, owner, repo, rulesetID, true)
+	rulesetID, err := parseID(state.RulesetID.ValueString())
 	if err != nil {
-		resp.Diagnostics.AddError("Error reading ruleset", err.Error())
+		resp.Diagnostics.AddError("Invalid ruleset ID", err.Error())
 		return
 	}
 
-	// Reset to default merge methods (all methods allowed)
-	defaultMethods := []github.PullRequestMergeMethod{
-		github.PullRequestMergeMethodMerge,
-		github.PullRequestMergeMethodSquash,
-		github.PullRequestMergeMethodRebase,
-	}
-
-	if ruleset.Rules == nil {
-		ruleset.Rules = &github.RepositoryRulesetRules{}
-	}
-	if ruleset.Rules.PullRequest == nil {
-		ruleset.Rules.PullRequest = &github.PullRequestRuleParameters{}
-	}
-	ruleset.Rules.PullRequest.AllowedMergeMethods = defaultMethods
-
-	_, _, err = r.client.Repositories.UpdateRuleset(ctx, owner, repo, rulesetID, *ruleset)
-	if err != nil {
-		resp.Diagnostics.AddError("Error resetting merge methods", err.Error())
-		return
-	}
+	key := githubclient.RulesetKey{Owner: owner, Repo: repo, ID: rulesetID}
+	resp.Diagnostics.Append(destroyMergeMethods(ctx, r.client, key, state.OnDestroy, req.Private)...)
 }
 
 func (r *rulesetAllowedMergeMethodsResource) ImportState(
@@ -241,52 +406,585 @@ func (r *rulesetAllowedMergeMethodsResource) ImportState(
 	req resource.ImportStateRequest,
 	resp *resource.ImportStateResponse,
 ) {
-	parts := strings.Split(req.ID, ":")
-	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+	repository, ruleset, ok := strings.Cut(req.ID, ":")
+	owner, repo, err := splitRepository(types.StringNull(), types.StringValue(repository), r.client.Owner)
+	if !ok || err != nil || repository == "" || ruleset == "" {
 		resp.Diagnostics.AddError(
 			"Unexpected Import Identifier",
-			fmt.Sprintf("Expected import identifier with format: repo:ruleset_id. Got: %q", req.ID),
+			fmt.Sprintf("Expected import identifier with format: [owner/]repo:ruleset_id or [owner/]repo:ruleset_name. Got: %q", req.ID),
 		)
 		return
 	}
 
-	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("repository"), parts[0])...)
-	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("ruleset_id"), parts[1])...)
+	rulesetID := ruleset
+	if _, err := parseID(ruleset); err != nil {
+		id, err := r.client.FindRulesetID(ctx, owner, repo, ruleset)
+		if err != nil {
+			resp.Diagnostics.AddError(rulesetWriteErrorDiagnostic("Error looking up ruleset", err))
+			return
+		}
+		rulesetID = fmt.Sprintf("%d", id)
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("ruleset_name"), ruleset)...)
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("repository"), repository)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("ruleset_id"), rulesetID)...)
+}
+
+func (r *rulesetAllowedMergeMethodsResource) UpgradeState(_ context.Context) map[int64]resource.StateUpgrader {
+	return map[int64]resource.StateUpgrader{
+		// Version 0 had no owner attribute and used repo:ruleset_id as ID.
+		0: {
+			PriorSchema: &schema.Schema{
+				Attributes: map[string]schema.Attribute{
+					"repository":            schema.StringAttribute{Required: true},
+					"ruleset_id":            schema.StringAttribute{Optional: true, Computed: true},
+					"ruleset_name":          schema.StringAttribute{Optional: true},
+					"allowed_merge_methods": schema.SetAttribute{ElementType: types.StringType, Required: true},
+					"force_update":          schema.StringAttribute{Optional: true},
+					"drift_mode":            schema.StringAttribute{Optional: true},
+					"on_destroy":            schema.StringAttribute{Optional: true},
+					"ruleset_fingerprint":   schema.StringAttribute{Computed: true},
+					"id":                    schema.StringAttribute{Computed: true},
+				},
+			},
+			StateUpgrader: r.upgradeStateV0,
+		},
+	}
+}
+
+func (r *rulesetAllowedMergeMethodsResource) upgradeStateV0(
+	ctx context.Context,
+	req resource.UpgradeStateRequest,
+	resp *resource.UpgradeStateResponse,
+) {
+	var prior struct {
+		Repository          types.String `tfsdk:"repository"`
+		RulesetID           types.String `tfsdk:"ruleset_id"`
+		RulesetName         types.String `tfsdk:"ruleset_name"`
+		AllowedMergeMethods types.Set    `tfsdk:"allowed_merge_methods"`
+		ForceUpdate         types.String `tfsdk:"force_update"`
+		DriftMode           types.String `tfsdk:"drift_mode"`
+		OnDestroy           types.String `tfsdk:"on_destroy"`
+		RulesetFingerprint  types.String `tfsdk:"ruleset_fingerprint"`
+		ID                  types.String `tfsdk:"id"`
+	}
+	resp.Diagnostics.Append(req.State.Get(ctx, &prior)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	state := rulesetAllowedMergeMethodsResourceModel{
+		Owner:               types.StringNull(),
+		Repository:          prior.Repository,
+		RulesetID:           prior.RulesetID,
+		RulesetName:         prior.RulesetName,
+		AllowedMergeMethods: prior.AllowedMergeMethods,
+		ForceUpdate:         prior.ForceUpdate,
+		DriftMode:           prior.DriftMode,
+		OnDestroy:           prior.OnDestroy,
+		RulesetFingerprint:  prior.RulesetFingerprint,
+		ID:                  prior.ID,
+	}
+	// The provider may not be configured yet when upgrading; Read then
+	// rewrites the ID once the owner is known.
+	if r.client != nil {
+		state.ID = types.StringValue(r.resourceID(&state))
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, state)...)
+}
+
+// resourceID returns the ID of the resource, owner/repo:ruleset_id. If owner
+// and repository conflict, repository is used as given.
+func (r *rulesetAllowedMergeMethodsResource) resourceID(model *rulesetAllowedMergeMethodsResourceModel) string {
+	owner, repo, err := splitRepository(model.Owner, model.Repository, r.client.Owner)
+	if err != nil {
+		return fmt.Sprintf("%s:%s", model.Repository.ValueString(), model.RulesetID.ValueString())
+	}
+	return fmt.Sprintf("%s/%s:%s", owner, repo, model.RulesetID.ValueString())
 }
 
+// upsert writes the planned merge methods and returns the methods the ruleset
+// allowed before.
 func (r *rulesetAllowedMergeMethodsResource) upsert(
 	ctx context.Context,
 	plan *rulesetAllowedMergeMethodsResourceModel,
-) error {
-	owner := r.client.Owner
-	repo := plan.Repository.ValueString()
+) ([]string, error) {
+	owner, repo, err := splitRepository(plan.Owner, plan.Repository, r.client.Owner)
+	if err != nil {
+		return nil, err
+	}
+
+	// ruleset_id is unknown when it has to be resolved from ruleset_name.
+	if plan.RulesetID.IsUnknown() || plan.RulesetID.IsNull() {
+		id, err := r.client.FindRulesetID(ctx, owner, repo, plan.RulesetName.ValueString())
+		if err != nil {
+			return nil, err
+		}
+		plan.RulesetID = types.StringValue(fmt.Sprintf("%d", id))
+	}
 
 	rulesetID, err := parseID(plan.RulesetID.ValueString())
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	ruleset, _, err := r.client.Repositories.GetRuleset(ctx, owner, repo, rulesetID, true)
+	key := githubclient.RulesetKey{Owner: owner, Repo: repo, ID: rulesetID}
+	previous, fingerprint, err := writeMergeMethods(ctx, r.client, key, extractMethodsFromSet(plan.AllowedMergeMethods))
 	if err != nil {
-		return err
+		return nil, err
 	}
+	plan.RulesetFingerprint = fingerprint
+	return previous, nil
+}
 
-	var methods []github.PullRequestMergeMethod
-	for _, v := range plan.AllowedMergeMethods.Elements() {
-		s, _ := v.(types.String)
-		methods = append(methods, github.PullRequestMergeMethod(s.ValueString()))
+// allMergeMethods is what GitHub allows when a ruleset does not restrict the
+// merge methods.
+var allMergeMethods = []string{
+	string(github.PullRequestMergeMethodMerge),
+	string(github.PullRequestMergeMethodSquash),
+	string(github.PullRequestMergeMethodRebase),
+}
+
+// on_destroy values control what Delete does with the merge methods.
+const (
+	// onDestroyRestorePrevious restores the methods recorded at create or
+	// import time.
+	onDestroyRestorePrevious = "restore_previous"
+	// onDestroyAllowAll allows every merge method.
+	onDestroyAllowAll = "allow_all"
+	// onDestroyLeaveAsIs keeps the merge methods as they are.
+	onDestroyLeaveAsIs = "leave_as_is"
+)
+
+var onDestroyModes = []string{onDestroyRestorePrevious, onDestroyAllowAll, onDestroyLeaveAsIs}
+
+// privateKeyPreviousMergeMethods is the private state key holding the merge
+// methods the ruleset allowed before the resource took it over.
+const privateKeyPreviousMergeMethods = "previous_merge_methods"
+
+// setPreviousMergeMethods records the merge methods the ruleset allowed before
+// the resource took it over. A ruleset without merge methods allows them all.
+func setPreviousMergeMethods(ctx context.Context, private privateState, methods []string) diag.Diagnostics {
+	if len(methods) == 0 {
+		methods = allMergeMethods
 	}
+	value, _ := json.Marshal(methods)
+	return private.SetKey(ctx, privateKeyPreviousMergeMethods, value)
+}
 
-	if ruleset.Rules == nil {
-		ruleset.Rules = &github.RepositoryRulesetRules{}
+// getPreviousMergeMethods returns the methods recorded by
+// setPreviousMergeMethods, or nil if there are none.
+func getPreviousMergeMethods(ctx context.Context, private privateState) ([]string, diag.Diagnostics) {
+	value, diags := private.GetKey(ctx, privateKeyPreviousMergeMethods)
+	if diags.HasError() || len(value) == 0 {
+		return nil, diags
 	}
-	if ruleset.Rules.PullRequest == nil {
-		ruleset.Rules.PullRequest = &github.PullRequestRuleParameters{}
+
+	var methods []string
+	if err := json.Unmarshal(value, &methods); err != nil {
+		diags.AddError("Invalid private state", err.Error())
+	}
+	return methods, diags
+}
+
+// destroyMergeMethods handles the merge methods of the ruleset identified by
+// key when the resource is destroyed, according to onDestroy.
+func destroyMergeMethods(
+	ctx context.Context,
+	client *githubclient.Client,
+	key githubclient.RulesetKey,
+	onDestroy types.String,
+	private privateState,
+) diag.Diagnostics {
+	previous, diags := getPreviousMergeMethods(ctx, private)
+	if diags.HasError() {
+		return diags
 	}
-	ruleset.Rules.PullRequest.AllowedMergeMethods = methods
 
-	_, _, err = r.client.Repositories.UpdateRuleset(ctx, owner, repo, rulesetID, *ruleset)
-	return err
+	methods, warning := mergeMethodsOnDestroy(onDestroy, previous)
+	if warning != "" {
+		diags.AddWarning("Previous merge methods unknown", warning)
+	}
+	if methods == nil {
+		return diags
+	}
+
+	_, _, err := writeMergeMethods(ctx, client, key, methods)
+	if isRulesetGone(err) {
+		// Nothing left to reset.
+		return diags
+	}
+	if err != nil {
+		diags.AddError(rulesetWriteErrorDiagnostic("Error resetting merge methods", err))
+	}
+	return diags
+}
+
+// mergeMethodsOnDestroy returns the merge methods to write when the resource
+// is destroyed, or nil if the ruleset is left as is. previous is nil for
+// resources created before the previous methods were recorded; all methods
+// are allowed for them, and a warning explaining that is returned.
+func mergeMethodsOnDestroy(onDestroy types.String, previous []string) ([]string, string) {
+	mode := onDestroyRestorePrevious
+	if !onDestroy.IsNull() && !onDestroy.IsUnknown() {
+		mode = onDestroy.ValueString()
+	}
+
+	switch mode {
+	case onDestroyLeaveAsIs:
+		return nil, ""
+	case onDestroyAllowAll:
+		return allMergeMethods, ""
+	}
+
+	if previous == nil {
+		return allMergeMethods, "The merge methods the ruleset allowed before Terraform managed it were not recorded, " +
+			"so all merge methods are allowed again. Re-import the resource to record them."
+	}
+	return previous, ""
+}
+
+// mergeMethodsRefresh is the result of refreshMergeMethods.
+type mergeMethodsRefresh struct {
+	AllowedMergeMethods types.Set
+	RulesetFingerprint  types.String
+	// Restored reports whether the merge methods were written back.
+	Restored bool
+	// Gone reports that the ruleset or its repository no longer exists.
+	Gone bool
+}
+
+// refreshMergeMethods reads the merge methods of the ruleset identified by key
+// and handles drift from expected according to driftMode. The ruleset as read
+// is returned together with the values to store in state. If the ruleset no
+// longer exists, only Gone is set.
+func refreshMergeMethods(
+	ctx context.Context,
+	client *githubclient.Client,
+	key githubclient.RulesetKey,
+	expected types.Set,
+	driftMode string,
+) (*githubclient.Ruleset, mergeMethodsRefresh, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	var result mergeMethodsRefresh
+
+	ruleset, err := client.GetRuleset(ctx, key)
+	if isRulesetGone(err) {
+		result.Gone = true
+		return nil, result, diags
+	}
+	if err != nil {
+		diags.AddError(githubErrorDiagnostic("Error reading a ruleset", err))
+		return nil, result, diags
+	}
+
+	currentMethods, _, err := rulesetMergeMethods(ruleset)
+	if err != nil {
+		diags.AddError("Error reading a ruleset", err.Error())
+		return nil, result, diags
+	}
+
+	fingerprint, err := mergeMethodsFingerprint(ruleset)
+	if err != nil {
+		diags.AddError("Error reading a ruleset", err.Error())
+		return nil, result, diags
+	}
+	result.RulesetFingerprint = types.StringValue(fingerprint)
+
+	// Check if current methods differ from expected methods. Nothing is
+	// expected yet right after import, the current methods are adopted then.
+	expectedMethods := extractMethodsFromSet(expected)
+	if !expected.IsNull() && !methodsEqual(currentMethods, expectedMethods) {
+		switch driftMode {
+		case driftModeRestore:
+			// Methods have been reset by GitHub, restore them
+			_, fingerprint, err := writeMergeMethods(ctx, client, key, expectedMethods)
+			if err != nil {
+				diags.AddWarning(
+					"Merge methods were reset",
+					fmt.Sprintf("GitHub reset the merge methods, attempted to restore but failed: %v", err),
+				)
+			} else {
+				// Successfully restored, use expected methods
+				currentMethods = expectedMethods
+				result.RulesetFingerprint = fingerprint
+				result.Restored = true
+			}
+		case driftModeIgnore:
+			currentMethods = expectedMethods
+		}
+		// In report mode the actual methods are stored, so the plan shows
+		// the drift and Update fixes it during apply.
+	}
+
+	result.AllowedMergeMethods = convertToSet(currentMethods)
+	return ruleset, result, diags
+}
+
+// writeMergeMethods sets the allowed merge methods of the ruleset identified
+// by key. It returns the methods the ruleset allowed before and the
+// fingerprint of the ruleset as written.
+func writeMergeMethods(
+	ctx context.Context,
+	client *githubclient.Client,
+	key githubclient.RulesetKey,
+	methods []string,
+) ([]string, types.String, error) {
+	mergeMethods := make([]github.PullRequestMergeMethod, 0, len(methods))
+	for _, method := range methods {
+		mergeMethods = append(mergeMethods, github.PullRequestMergeMethod(method))
+	}
+
+	var previous []string
+	updated, err := client.ModifyRuleset(ctx, key, func(ruleset *githubclient.Ruleset) error {
+		current, ok, err := rulesetMergeMethods(ruleset)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			// GitHub allows every method when the ruleset does not say.
+			current = allMergeMethods
+		}
+		previous = current
+		return setAllowedMergeMethods(ruleset, mergeMethods)
+	})
+	if err != nil {
+		return nil, types.StringNull(), err
+	}
+
+	fingerprint, err := mergeMethodsFingerprint(updated)
+	if err != nil {
+		return nil, types.StringNull(), err
+	}
+	return previous, types.StringValue(fingerprint), nil
+}
+
+// rulesetMergeMethods returns the allowed merge methods of the ruleset's
+// pull_request rule. It reports whether the ruleset sets them at all.
+func rulesetMergeMethods(ruleset *githubclient.Ruleset) ([]string, bool, error) {
+	rule := ruleset.Rule(string(github.RulesetRuleTypePullRequest))
+	if rule == nil {
+		return nil, false, nil
+	}
+
+	var methods []string
+	ok, err := rule.Parameter("allowed_merge_methods", &methods)
+	return methods, ok, err
+}
+
+// disabledMergeMethods returns the methods that the repository settings
+// disable. Settings not visible to the credentials are not reported.
+func disabledMergeMethods(repository *github.Repository, methods []string) []string {
+	enabled := map[string]*bool{
+		string(github.PullRequestMergeMethodMerge):  repository.AllowMergeCommit,
+		string(github.PullRequestMergeMethodSquash): repository.AllowSquashMerge,
+		string(github.PullRequestMergeMethodRebase): repository.AllowRebaseMerge,
+	}
+
+	var disabled []string
+	for _, method := range methods {
+		if allowed := enabled[method]; allowed != nil && !*allowed {
+			disabled = append(disabled, method)
+		}
+	}
+	return disabled
+}
+
+// mergeMethodsFingerprint fingerprints the pull_request rule, which carries
+// the merge methods, without the merge methods themselves and without the
+// parameters the pull request overlay manages, so writes of the overlay do not
+// plan merge method updates. Other rules are left out for the same reason.
+func mergeMethodsFingerprint(ruleset *githubclient.Ruleset) (string, error) {
+	return rulesetFingerprint(ruleset, func(rule *githubclient.RulesetRule) bool {
+		if rule.Type() != string(github.RulesetRuleTypePullRequest) {
+			return false
+		}
+		rule.DeleteParameter("allowed_merge_methods")
+		for _, name := range pullRequestOverlayParameters {
+			rule.DeleteParameter(name)
+		}
+		return true
+	})
+}
+
+// setAllowedMergeMethods overwrites allowed_merge_methods of the pull_request
+// rule, adding the rule if the ruleset has none. Every other rule and
+// parameter, including ones go-github does not know about, is kept as is.
+func setAllowedMergeMethods(ruleset *githubclient.Ruleset, methods []github.PullRequestMergeMethod) error {
+	rule := ruleset.Rule(string(github.RulesetRuleTypePullRequest))
+	if rule == nil {
+		var err error
+		rule, err = githubclient.NewRulesetRule(string(github.RulesetRuleTypePullRequest), github.PullRequestRuleParameters{})
+		if err != nil {
+			return err
+		}
+		ruleset.SetRule(rule)
+	}
+	return rule.SetParameter("allowed_merge_methods", methods)
+}
+
+// splitRepository returns the owner and name of a repository. repository may
+// be given as owner/repo; otherwise owner is used, or defaultOwner if owner is
+// not set either.
+func splitRepository(owner, repository types.String, defaultOwner string) (string, string, error) {
+	repoOwner, repo, ok := strings.Cut(repository.ValueString(), "/")
+	if !ok {
+		repo = repoOwner
+		repoOwner = ""
+	}
+	if repo == "" || strings.Contains(repo, "/") || (ok && repoOwner == "") {
+		return "", "", fmt.Errorf("repository must be 'repo' or 'owner/repo', got %q", repository.ValueString())
+	}
+
+	switch {
+	case repoOwner != "" && !owner.IsNull() && owner.ValueString() != repoOwner:
+		return "", "", fmt.Errorf("owner %q conflicts with the owner %q in repository %q",
+			owner.ValueString(), repoOwner, repository.ValueString())
+	case repoOwner != "":
+		return repoOwner, repo, nil
+	case !owner.IsNull():
+		return owner.ValueString(), repo, nil
+	}
+	return defaultOwner, repo, nil
+}
+
+// requiresReplaceIfRepositoryChanged replaces the resource when owner and
+// repository select another repository. Writing the same repository
+// differently, e.g. as owner/repo instead of setting owner, is an update.
+func requiresReplaceIfRepositoryChanged() planmodifier.String {
+	return stringplanmodifier.RequiresReplaceIf(
+		func(ctx context.Context, req planmodifier.StringRequest, resp *stringplanmodifier.RequiresReplaceIfFuncResponse) {
+			var planOwner, planRepository, stateOwner, stateRepository types.String
+			resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("owner"), &planOwner)...)
+			resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("repository"), &planRepository)...)
+			resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("owner"), &stateOwner)...)
+			resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("repository"), &stateRepository)...)
+			if resp.Diagnostics.HasError() {
+				return
+			}
+			if planOwner.IsUnknown() || planRepository.IsUnknown() {
+				resp.RequiresReplace = true
+				return
+			}
+
+			// Both sides fall back to the same provider owner.
+			planned, plannedRepo, err := splitRepository(planOwner, planRepository, "")
+			if err != nil {
+				return
+			}
+			current, currentRepo, err := splitRepository(stateOwner, stateRepository, "")
+			resp.RequiresReplace = err != nil || planned != current || plannedRepo != currentRepo
+		},
+		"Selecting another repository replaces the resource.",
+		"Selecting another repository replaces the resource.",
+	)
+}
+
+// rulesetIDPlanModifier keeps the resolved ruleset_id while ruleset_name is
+// unchanged, so the ID is only looked up again when the name changes.
+type rulesetIDPlanModifier struct{}
+
+func (m rulesetIDPlanModifier) Description(_ context.Context) string {
+	return "Keeps the resolved ruleset ID unless ruleset_name changes."
+}
+
+func (m rulesetIDPlanModifier) MarkdownDescription(ctx context.Context) string {
+	return m.Description(ctx)
+}
+
+func (m rulesetIDPlanModifier) PlanModifyString(
+	ctx context.Context,
+	req planmodifier.StringRequest,
+	resp *planmodifier.StringResponse,
+) {
+	if !req.PlanValue.IsUnknown() || req.StateValue.IsNull() {
+		return
+	}
+
+	var planName, stateName types.String
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("ruleset_name"), &planName)...)
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("ruleset_name"), &stateName)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if planName.Equal(stateName) {
+		resp.PlanValue = req.StateValue
+	}
+}
+
+// rulesetWriteErrorDiagnostic returns the diagnostic summary and detail for an
+// error returned while modifying a ruleset.
+func rulesetWriteErrorDiagnostic(summary string, err error) (string, string) {
+	var conflictErr *githubclient.RulesetConflictError
+	if errors.As(err, &conflictErr) {
+		return "Ruleset modified concurrently",
+			fmt.Sprintf("Ruleset %s kept being changed by someone else while it was being updated (%d attempts). "+
+				"Another tool such as github_repository_ruleset or a person in the GitHub UI may be editing it at the same time. "+
+				"Run terraform apply again once the other change has finished.", conflictErr.Key, conflictErr.Attempts)
+	}
+	var nameErr *githubclient.RulesetNameError
+	if errors.As(err, &nameErr) {
+		if len(nameErr.Matches) == 0 {
+			return "Ruleset not found", fmt.Sprintf("%s. Check ruleset_name; only rulesets defined on the repository itself can be managed.", nameErr)
+		}
+		return "Ambiguous ruleset name", fmt.Sprintf("%s. Set ruleset_id instead of ruleset_name to select one of them.", nameErr)
+	}
+	return githubErrorDiagnostic(summary, err)
+}
+
+// isRulesetGone reports whether err means that the ruleset or its repository
+// no longer exists. 410 Gone is returned for archived or transferred
+// repositories.
+func isRulesetGone(err error) bool {
+	var errResp *github.ErrorResponse
+	if !errors.As(err, &errResp) || errResp.Response == nil {
+		return false
+	}
+	switch errResp.Response.StatusCode {
+	case http.StatusNotFound, http.StatusGone:
+		return true
+	}
+	return false
+}
+
+// githubErrorDiagnostic returns the diagnostic summary and detail for a failed
+// GitHub API call, explaining how to resolve the common failures.
+func githubErrorDiagnostic(summary string, err error) (string, string) {
+	var rateLimitErr *github.RateLimitError
+	var abuseErr *github.AbuseRateLimitError
+	var errResp *github.ErrorResponse
+
+	switch {
+	case errors.As(err, &rateLimitErr):
+		return "GitHub rate limit exceeded",
+			fmt.Sprintf("The GitHub API rate limit is exhausted until %s. "+
+				"Wait for the reset, or raise max_retries on the provider to wait it out automatically. Error: %s",
+				rateLimitErr.Rate.Reset.Format(time.RFC3339), err)
+	case errors.As(err, &abuseErr):
+		return "GitHub secondary rate limit exceeded",
+			"GitHub throttled the requests because too many were sent in a short time. " +
+				"Lower terraform's -parallelism, set write_delay_ms on the provider or raise max_retries. Error: " + err.Error()
+	case errors.As(err, &errResp) && errResp.Response != nil:
+		switch status := errResp.Response.StatusCode; {
+		case status == http.StatusUnauthorized:
+			return "GitHub authentication failed",
+				"GitHub rejected the credentials. Make sure the token has not expired or been revoked, " +
+					"or that the GitHub App is still installed. Error: " + err.Error()
+		case status == http.StatusForbidden:
+			return "Access to the ruleset denied",
+				"The credentials lack permission to manage rulesets. Repository rulesets need the " +
+					"\"Administration\" repository permission, organization rulesets the \"Administration\" " +
+					"organization permission (or the repo and admin:org scopes for classic tokens). Error: " + err.Error()
+		case status >= http.StatusInternalServerError:
+			return "GitHub server error",
+				"GitHub failed to process the request. This is usually temporary; run terraform again later " +
+					"or check https://www.githubstatus.com. Error: " + err.Error()
+		}
+	}
+	return summary, err.Error()
 }
 
 func parseID(id string) (int64, error) {
@@ -354,19 +1052,3 @@ func methodsEqual(a, b []string) bool {
 	}
 	return true
 }
-
-// restoreMergeMethods restores the merge methods to the expected values
-func (r *rulesetAllowedMergeMethodsResource) restoreMergeMethods(
-	ctx context.Context,
-	repo string,
-	rulesetID int64,
-	expectedMethods []string,
-) error {
-	plan := &rulesetAllowedMergeMethodsResourceModel{
-		Repository:          types.StringValue(repo),
-		RulesetID:           types.StringValue(fmt.Sprintf("%d", rulesetID)),
-		AllowedMergeMethods: convertToSet(expectedMethods),
-	}
-
-	return r.upsert(ctx, plan)
-}
@@ -2,13 +2,22 @@ package provider
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/google/go-github/v74/github"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
 	"github.com/knowledge-work/terraform-provider-kw-github/internal/githubclient"
 )
 
@@ -67,7 +76,7 @@ func TestResourceRulesetAllowedMergeMethodsWithMock(t *testing.T) {
 			AllowedMergeMethods: convertToSet([]string{"merge"}),
 		}
 
-		err := resource.upsert(context.Background(), plan)
+		_, err := resource.upsert(context.Background(), plan)
 		if err != nil {
 			t.Fatalf("upsert failed: %v", err)
 		}
@@ -97,7 +106,7 @@ func TestResourceRulesetAllowedMergeMethodsWithMock(t *testing.T) {
 			AllowedMergeMethods: convertToSet([]string{"merge", "squash", "rebase"}), // default methods
 		}
 
-		err := resource.upsert(context.Background(), plan)
+		_, err := resource.upsert(context.Background(), plan)
 		if err != nil {
 			t.Fatalf("Delete (via upsert) failed: %v", err)
 		}
@@ -135,7 +144,7 @@ func TestResourceRulesetAllowedMergeMethodsWithMock(t *testing.T) {
 			AllowedMergeMethods: convertToSet([]string{"merge"}),
 		}
 
-		err := resource.upsert(context.Background(), plan)
+		_, err := resource.upsert(context.Background(), plan)
 		if err == nil {
 			t.Error("Expected upsert to fail with 404 error")
 		}
@@ -240,3 +249,676 @@ func TestMethodsEqual(t *testing.T) {
 		})
 	}
 }
+
+// Rules and parameters unknown to go-github must survive upsert unchanged.
+func TestUpsertPreservesUnknownRules(t *testing.T) {
+	const unknownRule = `{"type":"future_rule","parameters":{"nested":{"values":[1,2,3]},"flag":true}}`
+
+	var putBody map[string]json.RawMessage
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/owner/repo/rulesets/123", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case "GET":
+			fmt.Fprintf(w, `{
+				"id": 123,
+				"name": "test-ruleset",
+				"target": "branch",
+				"enforcement": "active",
+				"future_field": {"keep": "me"},
+				"rules": [
+					%s,
+					{"type": "pull_request", "parameters": {
+						"allowed_merge_methods": ["merge", "squash", "rebase"],
+						"required_approving_review_count": 2,
+						"required_reviewers": [{"minimum_approvals": 1, "file_patterns": ["*"], "reviewer": {"id": 7, "type": "Team"}}]
+					}},
+					{"type": "deletion"}
+				]
+			}`, unknownRule)
+		case "PUT":
+			if err := json.NewDecoder(r.Body).Decode(&putBody); err != nil {
+				t.Errorf("failed to decode PUT body: %v", err)
+			}
+			fmt.Fprint(w, `{"id": 123}`)
+		}
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := github.NewClient(nil)
+	client.BaseURL, _ = client.BaseURL.Parse(server.URL + "/")
+
+	resource := &rulesetAllowedMergeMethodsResource{
+		client: &githubclient.Client{Client: client, Owner: "owner"},
+	}
+	plan := &rulesetAllowedMergeMethodsResourceModel{
+		Repository:          types.StringValue("repo"),
+		RulesetID:           types.StringValue("123"),
+		AllowedMergeMethods: convertToSet([]string{"squash"}),
+	}
+
+	if _, err := resource.upsert(context.Background(), plan); err != nil {
+		t.Fatalf("upsert failed: %v", err)
+	}
+
+	if string(putBody["future_field"]) != `{"keep":"me"}` {
+		t.Errorf("Expected unknown top-level field to be kept, got %s", putBody["future_field"])
+	}
+
+	var rules []json.RawMessage
+	if err := json.Unmarshal(putBody["rules"], &rules); err != nil {
+		t.Fatalf("failed to decode rules: %v", err)
+	}
+	if len(rules) != 3 {
+		t.Fatalf("Expected 3 rules, got %d: %s", len(rules), putBody["rules"])
+	}
+	if string(rules[0]) != unknownRule {
+		t.Errorf("Expected unknown rule to be kept byte-for-byte, got %s", rules[0])
+	}
+	if string(rules[2]) != `{"type":"deletion"}` {
+		t.Errorf("Expected deletion rule to be kept, got %s", rules[2])
+	}
+
+	var pullRequest struct {
+		Parameters map[string]json.RawMessage `json:"parameters"`
+	}
+	if err := json.Unmarshal(rules[1], &pullRequest); err != nil {
+		t.Fatalf("failed to decode pull_request rule: %v", err)
+	}
+	if got := string(pullRequest.Parameters["allowed_merge_methods"]); got != `["squash"]` {
+		t.Errorf("Expected allowed_merge_methods to be updated, got %s", got)
+	}
+	if got := string(pullRequest.Parameters["required_approving_review_count"]); got != `2` {
+		t.Errorf("Expected required_approving_review_count to be kept, got %s", got)
+	}
+	wantReviewers := `[{"minimum_approvals":1,"file_patterns":["*"],"reviewer":{"id":7,"type":"Team"}}]`
+	if got := string(pullRequest.Parameters["required_reviewers"]); got != wantReviewers {
+		t.Errorf("Expected required_reviewers to be kept, got %s", got)
+	}
+}
+
+func TestUpsertResolvesRulesetName(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/owner/repo/rulesets", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"id": 123, "name": "main"}, {"id": 456, "name": "release"}, {"id": 789, "name": "release"}]`)
+	})
+	var putCalled bool
+	mux.HandleFunc("/repos/owner/repo/rulesets/123", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "PUT" {
+			putCalled = true
+		}
+		fmt.Fprint(w, `{"id": 123, "name": "main", "rules": []}`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := github.NewClient(nil)
+	client.BaseURL, _ = client.BaseURL.Parse(server.URL + "/")
+
+	r := &rulesetAllowedMergeMethodsResource{
+		client: &githubclient.Client{Client: client, Owner: "owner"},
+	}
+
+	tests := []struct {
+		name        string
+		rulesetName string
+		wantID      string
+		wantSummary string
+	}{
+		{name: "single match", rulesetName: "main", wantID: "123"},
+		{name: "no match", rulesetName: "missing", wantSummary: "Ruleset not found"},
+		{name: "multiple matches", rulesetName: "release", wantSummary: "Ambiguous ruleset name"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			putCalled = false
+			plan := &rulesetAllowedMergeMethodsResourceModel{
+				Repository:          types.StringValue("repo"),
+				RulesetID:           types.StringUnknown(),
+				RulesetName:         types.StringValue(test.rulesetName),
+				AllowedMergeMethods: convertToSet([]string{"squash"}),
+			}
+
+			_, err := r.upsert(context.Background(), plan)
+			if test.wantSummary != "" {
+				if err == nil {
+					t.Fatal("Expected an error")
+				}
+				if summary, _ := rulesetWriteErrorDiagnostic("Error creating ruleset", err); summary != test.wantSummary {
+					t.Errorf("Expected summary %q, got %q", test.wantSummary, summary)
+				}
+				if putCalled {
+					t.Error("Expected no PUT")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("upsert failed: %v", err)
+			}
+			if plan.RulesetID.ValueString() != test.wantID {
+				t.Errorf("Expected ruleset_id %s, got %s", test.wantID, plan.RulesetID)
+			}
+			if !putCalled {
+				t.Error("Expected PUT to be called")
+			}
+		})
+	}
+}
+
+func TestReadDriftModes(t *testing.T) {
+	tests := []struct {
+		driftMode   string
+		wantMethods []string
+		wantPut     bool
+	}{
+		{driftModeRestore, []string{"squash"}, true},
+		{driftModeReport, []string{"merge", "squash", "rebase"}, false},
+		{driftModeIgnore, []string{"squash"}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.driftMode, func(t *testing.T) {
+			var putCalled bool
+			mux := http.NewServeMux()
+			mux.HandleFunc("/repos/owner/repo/rulesets/123", func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == "PUT" {
+					putCalled = true
+				}
+				fmt.Fprint(w, `{"id": 123, "rules": [{"type": "pull_request", "parameters": {"allowed_merge_methods": ["merge", "squash", "rebase"]}}]}`)
+			})
+			server := httptest.NewServer(mux)
+			defer server.Close()
+
+			client := github.NewClient(nil)
+			client.BaseURL, _ = client.BaseURL.Parse(server.URL + "/")
+
+			r := &rulesetAllowedMergeMethodsResource{
+				client:           &githubclient.Client{Client: client, Owner: "owner"},
+				defaultDriftMode: test.driftMode,
+			}
+			state := rulesetAllowedMergeMethodsResourceModel{
+				Repository:          types.StringValue("repo"),
+				RulesetID:           types.StringValue("123"),
+				AllowedMergeMethods: convertToSet([]string{"squash"}),
+			}
+			result, diags := r.refresh(context.Background(), &state)
+			if diags.HasError() {
+				t.Fatalf("Read failed: %v", diags)
+			}
+
+			if got := extractMethodsFromSet(state.AllowedMergeMethods); !methodsEqual(got, test.wantMethods) {
+				t.Errorf("Expected methods %v in state, got %v", test.wantMethods, got)
+			}
+			if putCalled != test.wantPut || result.Restored != test.wantPut {
+				t.Errorf("Expected PUT called and restored to be %v, got %v and %v", test.wantPut, putCalled, result.Restored)
+			}
+		})
+	}
+}
+
+func TestRefreshRulesetErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		header      map[string]string
+		docURL      string
+		wantGone    bool
+		wantSummary string
+	}{
+		{name: "not found", status: http.StatusNotFound, wantGone: true},
+		{name: "gone", status: http.StatusGone, wantGone: true},
+		{name: "unauthorized", status: http.StatusUnauthorized, wantSummary: "GitHub authentication failed"},
+		{name: "forbidden", status: http.StatusForbidden, wantSummary: "Access to the ruleset denied"},
+		{name: "server error", status: http.StatusInternalServerError, wantSummary: "GitHub server error"},
+		{
+			name:   "rate limited",
+			status: http.StatusForbidden,
+			header: map[string]string{
+				"X-RateLimit-Limit":     "5000",
+				"X-RateLimit-Remaining": "0",
+				"X-RateLimit-Reset":     "1893456000",
+			},
+			wantSummary: "GitHub rate limit exceeded",
+		},
+		{
+			name:        "secondary rate limited",
+			status:      http.StatusForbidden,
+			header:      map[string]string{"Retry-After": "60"},
+			docURL:      "https://docs.github.com/rest/overview/rate-limits-for-the-rest-api#about-secondary-rate-limits",
+			wantSummary: "GitHub secondary rate limit exceeded",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			mux.HandleFunc("/repos/owner/repo/rulesets/123", func(w http.ResponseWriter, r *http.Request) {
+				for name, value := range test.header {
+					w.Header().Set(name, value)
+				}
+				w.WriteHeader(test.status)
+				fmt.Fprintf(w, `{"message": %q, "documentation_url": %q}`, http.StatusText(test.status), test.docURL)
+			})
+			server := httptest.NewServer(mux)
+			defer server.Close()
+
+			client := github.NewClient(nil)
+			client.BaseURL, _ = client.BaseURL.Parse(server.URL + "/")
+
+			r := &rulesetAllowedMergeMethodsResource{
+				client: &githubclient.Client{Client: client, Owner: "owner"},
+			}
+			state := rulesetAllowedMergeMethodsResourceModel{
+				Repository:          types.StringValue("repo"),
+				RulesetID:           types.StringValue("123"),
+				AllowedMergeMethods: convertToSet([]string{"squash"}),
+			}
+			result, diags := r.refresh(context.Background(), &state)
+
+			if result.Gone != test.wantGone {
+				t.Errorf("Expected gone to be %v", test.wantGone)
+			}
+			if test.wantGone {
+				if diags.HasError() {
+					t.Errorf("Expected no error, got %v", diags)
+				}
+				return
+			}
+			if !diags.HasError() {
+				t.Fatal("Expected an error")
+			}
+			if got := diags.Errors()[0].Summary(); got != test.wantSummary {
+				t.Errorf("Expected summary %q, got %q", test.wantSummary, got)
+			}
+		})
+	}
+}
+
+func TestRulesetWriteErrorDiagnostic(t *testing.T) {
+	err := fmt.Errorf("wrapped: %w", &githubclient.RulesetConflictError{
+		Key:      githubclient.RulesetKey{Owner: "owner", Repo: "repo", ID: 123},
+		Attempts: 3,
+	})
+	summary, detail := rulesetWriteErrorDiagnostic("Error updating ruleset", err)
+	if summary != "Ruleset modified concurrently" {
+		t.Errorf("Expected a conflict summary, got %q", summary)
+	}
+	if !strings.Contains(detail, "owner/repo/123") {
+		t.Errorf("Expected the detail to name the ruleset, got %q", detail)
+	}
+}
+
+func TestDestroyMergeMethods(t *testing.T) {
+	tests := []struct {
+		name        string
+		onDestroy   types.String
+		previous    []string
+		wantMethods []string
+		wantWarning bool
+	}{
+		{name: "default restores previous", onDestroy: types.StringNull(), previous: []string{"squash"}, wantMethods: []string{"squash"}},
+		{name: "restore previous", onDestroy: types.StringValue(onDestroyRestorePrevious), previous: []string{"merge", "squash"}, wantMethods: []string{"merge", "squash"}},
+		{name: "previous not recorded", onDestroy: types.StringValue(onDestroyRestorePrevious), wantMethods: allMergeMethods, wantWarning: true},
+		{name: "allow all", onDestroy: types.StringValue(onDestroyAllowAll), previous: []string{"squash"}, wantMethods: allMergeMethods},
+		{name: "leave as is", onDestroy: types.StringValue(onDestroyLeaveAsIs), previous: []string{"squash"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var written []string
+			mux := http.NewServeMux()
+			mux.HandleFunc("/repos/owner/repo/rulesets/123", func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == "PUT" {
+					var body struct {
+						Rules []struct {
+							Parameters struct {
+								AllowedMergeMethods []string `json:"allowed_merge_methods"`
+							} `json:"parameters"`
+						} `json:"rules"`
+					}
+					if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+						t.Errorf("failed to decode PUT body: %v", err)
+					}
+					written = body.Rules[0].Parameters.AllowedMergeMethods
+				}
+				fmt.Fprint(w, `{"id": 123, "rules": [{"type": "pull_request", "parameters": {"allowed_merge_methods": ["rebase"]}}]}`)
+			})
+			server := httptest.NewServer(mux)
+			defer server.Close()
+
+			client := github.NewClient(nil)
+			client.BaseURL, _ = client.BaseURL.Parse(server.URL + "/")
+
+			private := fakePrivateState{}
+			if test.previous != nil {
+				setPreviousMergeMethods(context.Background(), private, test.previous)
+			}
+
+			key := githubclient.RulesetKey{Owner: "owner", Repo: "repo", ID: 123}
+			diags := destroyMergeMethods(
+				context.Background(), &githubclient.Client{Client: client, Owner: "owner"}, key, test.onDestroy, private,
+			)
+			if diags.HasError() {
+				t.Fatalf("destroyMergeMethods failed: %v", diags)
+			}
+			if got := len(diags.Warnings()) > 0; got != test.wantWarning {
+				t.Errorf("Expected warning %v, got %v", test.wantWarning, diags)
+			}
+			if test.wantMethods == nil {
+				if written != nil {
+					t.Errorf("Expected no write, got %v", written)
+				}
+				return
+			}
+			if !methodsEqual(written, test.wantMethods) {
+				t.Errorf("Expected %v to be written, got %v", test.wantMethods, written)
+			}
+		})
+	}
+}
+
+func TestWriteMergeMethodsReturnsPrevious(t *testing.T) {
+	tests := []struct {
+		name         string
+		ruleset      string
+		wantPrevious []string
+	}{
+		{
+			name:         "methods set",
+			ruleset:      `{"id": 123, "rules": [{"type": "pull_request", "parameters": {"allowed_merge_methods": ["squash"]}}]}`,
+			wantPrevious: []string{"squash"},
+		},
+		{
+			name:         "no pull request rule",
+			ruleset:      `{"id": 123, "rules": []}`,
+			wantPrevious: allMergeMethods,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			mux.HandleFunc("/repos/owner/repo/rulesets/123", func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, test.ruleset)
+			})
+			server := httptest.NewServer(mux)
+			defer server.Close()
+
+			client := github.NewClient(nil)
+			client.BaseURL, _ = client.BaseURL.Parse(server.URL + "/")
+
+			key := githubclient.RulesetKey{Owner: "owner", Repo: "repo", ID: 123}
+			previous, _, err := writeMergeMethods(
+				context.Background(), &githubclient.Client{Client: client, Owner: "owner"}, key, []string{"merge"},
+			)
+			if err != nil {
+				t.Fatalf("writeMergeMethods failed: %v", err)
+			}
+			if !methodsEqual(previous, test.wantPrevious) {
+				t.Errorf("Expected previous %v, got %v", test.wantPrevious, previous)
+			}
+		})
+	}
+}
+
+func TestDisabledMergeMethods(t *testing.T) {
+	repository := &github.Repository{
+		AllowMergeCommit: github.Ptr(false),
+		AllowSquashMerge: github.Ptr(true),
+	}
+
+	tests := []struct {
+		methods []string
+		want    []string
+	}{
+		{[]string{"squash"}, nil},
+		{[]string{"merge", "squash"}, []string{"merge"}},
+		// allow_rebase_merge is not visible, so rebase is not reported.
+		{[]string{"rebase"}, nil},
+	}
+
+	for _, test := range tests {
+		if got := disabledMergeMethods(repository, test.methods); !methodsEqual(got, test.want) {
+			t.Errorf("disabledMergeMethods(%v) = %v, want %v", test.methods, got, test.want)
+		}
+	}
+}
+
+func TestSplitRepository(t *testing.T) {
+	tests := []struct {
+		owner      types.String
+		repository string
+		wantOwner  string
+		wantRepo   string
+		wantErr    bool
+	}{
+		{owner: types.StringNull(), repository: "repo", wantOwner: "provider-owner", wantRepo: "repo"},
+		{owner: types.StringValue("other"), repository: "repo", wantOwner: "other", wantRepo: "repo"},
+		{owner: types.StringNull(), repository: "other/repo", wantOwner: "other", wantRepo: "repo"},
+		{owner: types.StringValue("other"), repository: "other/repo", wantOwner: "other", wantRepo: "repo"},
+		{owner: types.StringValue("another"), repository: "other/repo", wantErr: true},
+		{owner: types.StringNull(), repository: "a/b/c", wantErr: true},
+		{owner: types.StringNull(), repository: "/repo", wantErr: true},
+		{owner: types.StringNull(), repository: "other/", wantErr: true},
+	}
+
+	for _, test := range tests {
+		owner, repo, err := splitRepository(test.owner, types.StringValue(test.repository), "provider-owner")
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("splitRepository(%s, %q) expected an error", test.owner, test.repository)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("splitRepository(%s, %q) failed: %v", test.owner, test.repository, err)
+			continue
+		}
+		if owner != test.wantOwner || repo != test.wantRepo {
+			t.Errorf("splitRepository(%s, %q) = %s, %s, want %s, %s",
+				test.owner, test.repository, owner, repo, test.wantOwner, test.wantRepo)
+		}
+	}
+}
+
+func TestUpgradeStateV0(t *testing.T) {
+	ctx := context.Background()
+
+	for _, test := range []struct {
+		name   string
+		client *githubclient.Client
+		wantID string
+	}{
+		{name: "configured", client: &githubclient.Client{Owner: "owner"}, wantID: "owner/repo:123"},
+		{name: "not configured", wantID: "repo:123"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			r := &rulesetAllowedMergeMethodsResource{client: test.client}
+
+			var schemaResp resource.SchemaResponse
+			r.Schema(ctx, resource.SchemaRequest{}, &schemaResp)
+			upgrader := r.UpgradeState(ctx)[0]
+
+			prior := tfsdk.State{
+				Schema: *upgrader.PriorSchema,
+				Raw:    tftypes.NewValue(upgrader.PriorSchema.Type().TerraformType(ctx), nil),
+			}
+			priorType := upgrader.PriorSchema.Type().(types.ObjectType)
+			diags := prior.Set(ctx, types.ObjectValueMust(priorType.AttrTypes, map[string]attr.Value{
+				"repository":            types.StringValue("repo"),
+				"ruleset_id":            types.StringValue("123"),
+				"ruleset_name":          types.StringNull(),
+				"allowed_merge_methods": convertToSet([]string{"squash"}),
+				"force_update":          types.StringNull(),
+				"drift_mode":            types.StringNull(),
+				"on_destroy":            types.StringNull(),
+				"ruleset_fingerprint":   types.StringValue("abc"),
+				"id":                    types.StringValue("repo:123"),
+			}))
+			if diags.HasError() {
+				t.Fatalf("Failed to build prior state: %v", diags)
+			}
+
+			resp := &resource.UpgradeStateResponse{
+				State: tfsdk.State{
+					Schema: schemaResp.Schema,
+					Raw:    tftypes.NewValue(schemaResp.Schema.Type().TerraformType(ctx), nil),
+				},
+			}
+			upgrader.StateUpgrader(ctx, resource.UpgradeStateRequest{State: &prior}, resp)
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("Upgrade failed: %v", resp.Diagnostics)
+			}
+
+			var state rulesetAllowedMergeMethodsResourceModel
+			if diags := resp.State.Get(ctx, &state); diags.HasError() {
+				t.Fatalf("Failed to read upgraded state: %v", diags)
+			}
+			if state.ID.ValueString() != test.wantID {
+				t.Errorf("Expected ID %s, got %s", test.wantID, state.ID)
+			}
+			if !state.Owner.IsNull() || state.Repository.ValueString() != "repo" || state.RulesetFingerprint.ValueString() != "abc" {
+				t.Errorf("Unexpected upgraded state: %+v", state)
+			}
+		})
+	}
+}
+
+func TestRetargetRequiresReplace(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/owner/repo/rulesets", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"id": 123, "name": "main"}, {"id": 456, "name": "release"}]`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := github.NewClient(nil)
+	client.BaseURL, _ = client.BaseURL.Parse(server.URL + "/")
+	r := &rulesetAllowedMergeMethodsResource{
+		client: &githubclient.Client{Client: client, Owner: "owner"},
+	}
+
+	ctx := context.Background()
+	var schemaResp resource.SchemaResponse
+	r.Schema(ctx, resource.SchemaRequest{}, &schemaResp)
+
+	model := func(owner, repository, rulesetID, rulesetName types.String) rulesetAllowedMergeMethodsResourceModel {
+		return rulesetAllowedMergeMethodsResourceModel{
+			Owner:               owner,
+			Repository:          repository,
+			RulesetID:           rulesetID,
+			RulesetName:         rulesetName,
+			AllowedMergeMethods: convertToSet([]string{"squash"}),
+			ForceUpdate:         types.StringNull(),
+			DriftMode:           types.StringNull(),
+			OnDestroy:           types.StringNull(),
+			RulesetFingerprint:  types.StringValue("abc"),
+			ID:                  types.StringValue("owner/repo:123"),
+		}
+	}
+	state := model(types.StringValue("owner"), types.StringValue("repo"), types.StringValue("123"), types.StringNull())
+	byName := model(types.StringNull(), types.StringValue("repo"), types.StringValue("123"), types.StringValue("old-main"))
+
+	tests := []struct {
+		name        string
+		state       rulesetAllowedMergeMethodsResourceModel
+		plan        rulesetAllowedMergeMethodsResourceModel
+		wantReplace []string
+		wantID      string
+	}{
+		{
+			name:   "same repository written as owner/repo",
+			state:  state,
+			plan:   model(types.StringNull(), types.StringValue("owner/repo"), types.StringValue("123"), types.StringNull()),
+			wantID: "123",
+		},
+		{
+			name:        "other repository",
+			state:       state,
+			plan:        model(types.StringValue("owner"), types.StringValue("other"), types.StringValue("123"), types.StringNull()),
+			wantReplace: []string{"repository"},
+			wantID:      "123",
+		},
+		{
+			name:        "other owner",
+			state:       state,
+			plan:        model(types.StringValue("someone"), types.StringValue("repo"), types.StringValue("123"), types.StringNull()),
+			wantReplace: []string{"owner"},
+			wantID:      "123",
+		},
+		{
+			name:        "other ruleset ID",
+			state:       state,
+			plan:        model(types.StringValue("owner"), types.StringValue("repo"), types.StringValue("456"), types.StringNull()),
+			wantReplace: []string{"ruleset_id"},
+			wantID:      "456",
+		},
+		{
+			name:        "name of another ruleset",
+			state:       byName,
+			plan:        model(types.StringNull(), types.StringValue("repo"), types.StringUnknown(), types.StringValue("release")),
+			wantReplace: []string{"ruleset_id"},
+			wantID:      "456",
+		},
+		{
+			name:   "new name of the same ruleset",
+			state:  byName,
+			plan:   model(types.StringNull(), types.StringValue("repo"), types.StringUnknown(), types.StringValue("main")),
+			wantID: "123",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			stateValue := tfsdk.State{Schema: schemaResp.Schema, Raw: tftypes.NewValue(schemaResp.Schema.Type().TerraformType(ctx), nil)}
+			planValue := tfsdk.Plan{Schema: schemaResp.Schema, Raw: tftypes.NewValue(schemaResp.Schema.Type().TerraformType(ctx), nil)}
+			if diags := stateValue.Set(ctx, test.state); diags.HasError() {
+				t.Fatal(diags)
+			}
+			if diags := planValue.Set(ctx, test.plan); diags.HasError() {
+				t.Fatal(diags)
+			}
+
+			var replace []string
+			for _, name := range []string{"owner", "repository", "ruleset_id"} {
+				attribute := schemaResp.Schema.Attributes[name].(schema.StringAttribute)
+				req := planmodifier.StringRequest{Path: path.Root(name), Plan: planValue, State: stateValue}
+				planValue.GetAttribute(ctx, req.Path, &req.PlanValue)
+				stateValue.GetAttribute(ctx, req.Path, &req.StateValue)
+
+				for _, modifier := range attribute.PlanModifiers {
+					resp := &planmodifier.StringResponse{PlanValue: req.PlanValue}
+					modifier.PlanModifyString(ctx, req, resp)
+					if resp.Diagnostics.HasError() {
+						t.Fatal(resp.Diagnostics)
+					}
+					if resp.RequiresReplace {
+						replace = append(replace, name)
+						break
+					}
+				}
+			}
+
+			resp := &resource.ModifyPlanResponse{Plan: planValue}
+			r.ModifyPlan(ctx, resource.ModifyPlanRequest{Plan: planValue, State: stateValue}, resp)
+			if resp.Diagnostics.HasError() {
+				t.Fatal(resp.Diagnostics)
+			}
+			for _, p := range resp.RequiresReplace {
+				replace = append(replace, p.String())
+			}
+
+			if fmt.Sprint(replace) != fmt.Sprint(test.wantReplace) {
+				t.Errorf("Expected replacement because of %v, got %v", test.wantReplace, replace)
+			}
+			var id types.String
+			resp.Plan.GetAttribute(ctx, path.Root("ruleset_id"), &id)
+			if id.ValueString() != test.wantID {
+				t.Errorf("Expected planned ruleset_id %s, got %s", test.wantID, id)
+			}
+		})
+	}
+}
@@ -0,0 +1,447 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-github/v74/github"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64default"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/knowledge-work/terraform-provider-kw-github/internal/githubclient"
+)
+
+var (
+	mergeQueueGroupingStrategies = []string{
+		string(github.MergeGroupingStrategyAllGreen),
+		string(github.MergeGroupingStrategyHeadGreen),
+	}
+	mergeQueueMergeMethods = []string{
+		string(github.MergeQueueMergeMethodMerge),
+		string(github.MergeQueueMergeMethodSquash),
+		string(github.MergeQueueMergeMethodRebase),
+	}
+)
+
+// mergeQueueParameters are the merge_queue rule parameters the resource
+// manages.
+var mergeQueueParameters = []string{
+	"check_response_timeout_minutes",
+	"grouping_strategy",
+	"max_entries_to_build",
+	"max_entries_to_merge",
+	"merge_method",
+	"min_entries_to_merge",
+	"min_entries_to_merge_wait_minutes",
+}
+
+func NewRulesetMergeQueueResource() resource.Resource {
+	return &rulesetMergeQueueResource{}
+}
+
+type rulesetMergeQueueResource struct {
+	client           *githubclient.Client
+	defaultDriftMode string
+}
+
+type rulesetMergeQueueResourceModel struct {
+	rulesetTargetModel
+	CheckResponseTimeoutMinutes  types.Int64  `tfsdk:"check_response_timeout_minutes"`
+	GroupingStrategy             types.String `tfsdk:"grouping_strategy"`
+	MaxEntriesToBuild            types.Int64  `tfsdk:"max_entries_to_build"`
+	MaxEntriesToMerge            types.Int64  `tfsdk:"max_entries_to_merge"`
+	MergeMethod                  types.String `tfsdk:"merge_method"`
+	MinEntriesToMerge            types.Int64  `tfsdk:"min_entries_to_merge"`
+	MinEntriesToMergeWaitMinutes types.Int64  `tfsdk:"min_entries_to_merge_wait_minutes"`
+	DriftMode                    types.String `tfsdk:"drift_mode"`
+	OnDestroy                    types.String `tfsdk:"on_destroy"`
+}
+
+func (r *rulesetMergeQueueResource) Metadata(
+	_ context.Context,
+	req resource.MetadataRequest,
+	resp *resource.MetadataResponse,
+) {
+	resp.TypeName = req.ProviderTypeName + "_ruleset_merge_queue"
+}
+
+func (r *rulesetMergeQueueResource) Schema(
+	_ context.Context,
+	_ resource.SchemaRequest,
+	resp *resource.SchemaResponse,
+) {
+	resp.Schema = schema.Schema{
+		Description: "Manages the merge_queue rule of a repository ruleset. Other rules are left untouched.",
+		Attributes: rulesetTargetAttributes(map[string]schema.Attribute{
+			"check_response_timeout_minutes": schema.Int64Attribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     int64default.StaticInt64(60),
+				Description: "Maximum time in minutes for a required status check to report a conclusion, between 1 and 360. Defaults to 60.",
+				Validators: []validator.Int64{
+					int64validator.Between(1, 360),
+				},
+			},
+			"grouping_strategy": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString(string(github.MergeGroupingStrategyAllGreen)),
+				Description: "Which pull requests' status checks must pass before a group is merged. 'ALLGREEN' requires every pull request in the group to pass, 'HEADGREEN' only the head of the group. Defaults to 'ALLGREEN'.",
+				Validators: []validator.String{
+					stringvalidator.OneOf(mergeQueueGroupingStrategies...),
+				},
+			},
+			"max_entries_to_build": schema.Int64Attribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     int64default.StaticInt64(5),
+				Description: "Maximum number of queued pull requests requesting checks at the same time, between 0 and 100. Defaults to 5.",
+				Validators: []validator.Int64{
+					int64validator.Between(0, 100),
+				},
+			},
+			"max_entries_to_merge": schema.Int64Attribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     int64default.StaticInt64(5),
+				Description: "Maximum number of pull requests merged together in a group, between 0 and 100. Defaults to 5.",
+				Validators: []validator.Int64{
+					int64validator.Between(0, 100),
+				},
+			},
+			"merge_method": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     stringdefault.StaticString(string(github.MergeQueueMergeMethodMerge)),
+				Description: "Method to use when merging changes from queued pull requests. Valid values are: 'MERGE', 'SQUASH', 'REBASE'. Defaults to 'MERGE'.",
+				Validators: []validator.String{
+					stringvalidator.OneOf(mergeQueueMergeMethods...),
+				},
+			},
+			"min_entries_to_merge": schema.Int64Attribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     int64default.StaticInt64(1),
+				Description: "Minimum number of pull requests merged together in a group, between 0 and 100. Defaults to 1.",
+				Validators: []validator.Int64{
+					int64validator.Between(0, 100),
+				},
+			},
+			"min_entries_to_merge_wait_minutes": schema.Int64Attribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     int64default.StaticInt64(5),
+				Description: "Time in minutes the merge queue waits after the first pull request is added before min_entries_to_merge is no longer required, between 0 and 360. Defaults to 5.",
+				Validators: []validator.Int64{
+					int64validator.Between(0, 360),
+				},
+			},
+			"drift_mode": driftModeAttribute("the merge queue settings were changed or removed"),
+			"on_destroy": ruleOnDestroyAttribute("restores the merge_queue rule as it was when the resource was created or imported, or removes it if the ruleset had none"),
+		}),
+	}
+}
+
+// ValidateConfig checks that min_entries_to_merge does not exceed
+// max_entries_to_merge, which the GitHub API would reject.
+func (r *rulesetMergeQueueResource) ValidateConfig(
+	ctx context.Context,
+	req resource.ValidateConfigRequest,
+	resp *resource.ValidateConfigResponse,
+) {
+	var config rulesetMergeQueueResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() || config.MinEntriesToMerge.IsUnknown() || config.MaxEntriesToMerge.IsUnknown() {
+		return
+	}
+
+	// Unset attributes take their defaults.
+	minEntries, maxEntries := int64(1), int64(5)
+	if !config.MinEntriesToMerge.IsNull() {
+		minEntries = config.MinEntriesToMerge.ValueInt64()
+	}
+	if !config.MaxEntriesToMerge.IsNull() {
+		maxEntries = config.MaxEntriesToMerge.ValueInt64()
+	}
+
+	if minEntries > maxEntries {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("min_entries_to_merge"),
+			"Invalid merge queue group size",
+			fmt.Sprintf("min_entries_to_merge (%d) must not be greater than max_entries_to_merge (%d).", minEntries, maxEntries),
+		)
+	}
+}
+
+func (r *rulesetMergeQueueResource) Configure(
+	_ context.Context,
+	req resource.ConfigureRequest,
+	resp *resource.ConfigureResponse,
+) {
+	if req.ProviderData == nil {
+		return
+	}
+	data := req.ProviderData.(*providerData)
+	r.client = data.client
+	r.defaultDriftMode = data.driftMode
+}
+
+func (r *rulesetMergeQueueResource) Create(
+	ctx context.Context,
+	req resource.CreateRequest,
+	resp *resource.CreateResponse,
+) {
+	var plan rulesetMergeQueueResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	previous, diags := r.upsert(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(setPreviousRule(ctx, resp.Private, previous, mergeQueueParameters)...)
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+}
+
+func (r *rulesetMergeQueueResource) Read(
+	ctx context.Context,
+	req resource.ReadRequest,
+	resp *resource.ReadResponse,
+) {
+	var state rulesetMergeQueueResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	found, diags := r.refresh(ctx, &state, resp.Private)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if !found {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// refresh updates state from GitHub and handles drift of the merge_queue rule
+// according to the drift mode. Right after import, the rule is adopted and
+// recorded in private for Delete. It returns false if the ruleset is gone, or
+// if the rule was removed and the drift mode lets the plan add it again.
+func (r *rulesetMergeQueueResource) refresh(
+	ctx context.Context,
+	state *rulesetMergeQueueResourceModel,
+	private privateState,
+) (bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	key, err := state.key(r.client.Owner)
+	if err != nil {
+		diags.AddError("Invalid ruleset", err.Error())
+		return false, diags
+	}
+
+	ruleset, diags := getRulesetTarget(ctx, r.client, key)
+	if ruleset == nil {
+		return false, diags
+	}
+
+	imported := state.ID.IsNull()
+	state.ID = types.StringValue(rulesetTargetID(key))
+
+	rule := ruleset.Rule(string(github.RulesetRuleTypeMergeQueue))
+	desired := state.parameters()
+
+	if imported {
+		if rule == nil {
+			diags.AddError("Merge queue rule not found", "Ruleset "+key.String()+" has no merge_queue rule.")
+			return false, diags
+		}
+		diags.Append(state.setFromRule(rule)...)
+		diags.Append(setPreviousRule(ctx, private, rule, mergeQueueParameters)...)
+	} else if drifted, err := parametersDrifted(rule, desired); err != nil {
+		diags.AddError("Error reading a ruleset", err.Error())
+	} else if drifted {
+		useActual, driftDiags := handleRuleDrift(
+			ctx, r.client, key, resolveDriftMode(state.DriftMode, r.defaultDriftMode),
+			func(ruleset *githubclient.Ruleset) error {
+				return setRuleParameters(ruleset, string(github.RulesetRuleTypeMergeQueue), desired)
+			},
+		)
+		diags.Append(driftDiags...)
+		if useActual {
+			if rule == nil {
+				// The rule was removed; let the plan add it again.
+				return false, diags
+			}
+			diags.Append(state.setFromRule(rule)...)
+		}
+	}
+	return true, diags
+}
+
+func (r *rulesetMergeQueueResource) Update(
+	ctx context.Context,
+	req resource.UpdateRequest,
+	resp *resource.UpdateResponse,
+) {
+	var plan rulesetMergeQueueResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	_, diags := r.upsert(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+}
+
+func (r *rulesetMergeQueueResource) Delete(
+	ctx context.Context,
+	req resource.DeleteRequest,
+	resp *resource.DeleteResponse,
+) {
+	var state rulesetMergeQueueResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.destroy(ctx, &state, req.Private)...)
+}
+
+// destroy restores the merge_queue rule recorded in private at create or
+// import time, or removes it if the ruleset had none, according to on_destroy.
+func (r *rulesetMergeQueueResource) destroy(
+	ctx context.Context,
+	state *rulesetMergeQueueResourceModel,
+	private privateState,
+) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	key, err := state.key(r.client.Owner)
+	if err != nil {
+		diags.AddError("Invalid ruleset", err.Error())
+		return diags
+	}
+
+	previous, diags := getPreviousRule(ctx, private)
+	if diags.HasError() {
+		return diags
+	}
+	if previous == nil {
+		if state.OnDestroy.ValueString() != onDestroyLeaveAsIs {
+			diags.AddWarning(
+				"Previous merge queue rule unknown",
+				"The merge_queue rule the ruleset had before Terraform managed it was not recorded, so the rule is removed.",
+			)
+		}
+		previous = &previousRule{}
+	}
+
+	diags.Append(destroyRule(
+		ctx, r.client, key, string(github.RulesetRuleTypeMergeQueue), mergeQueueParameters, state.OnDestroy, previous,
+	)...)
+	return diags
+}
+
+func (r *rulesetMergeQueueResource) ImportState(
+	ctx context.Context,
+	req resource.ImportStateRequest,
+	resp *resource.ImportStateResponse,
+) {
+	importRulesetTarget(ctx, req.ID, resp)
+}
+
+// upsert writes the merge_queue rule in plan and returns the rule as it was
+// before, or nil if the ruleset had none.
+func (r *rulesetMergeQueueResource) upsert(
+	ctx context.Context,
+	plan *rulesetMergeQueueResourceModel,
+) (*githubclient.RulesetRule, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	key, err := plan.key(r.client.Owner)
+	if err != nil {
+		diags.AddError("Invalid ruleset", err.Error())
+		return nil, diags
+	}
+
+	params := plan.parameters()
+	var previous *githubclient.RulesetRule
+	_, err = r.client.ModifyRuleset(ctx, key, func(ruleset *githubclient.Ruleset) error {
+		if rule := ruleset.Rule(string(github.RulesetRuleTypeMergeQueue)); rule != nil {
+			previous = rule.Clone()
+		}
+		return setRuleParameters(ruleset, string(github.RulesetRuleTypeMergeQueue), params)
+	})
+	if err != nil {
+		diags.AddError(rulesetWriteErrorDiagnostic("Error updating ruleset", err))
+		return nil, diags
+	}
+
+	plan.ID = types.StringValue(rulesetTargetID(key))
+	return previous, diags
+}
+
+// parameters returns the merge_queue rule parameters of the model, keyed by
+// their API name.
+func (m *rulesetMergeQueueResourceModel) parameters() map[string]any {
+	return map[string]any{
+		"check_response_timeout_minutes":    m.CheckResponseTimeoutMinutes.ValueInt64(),
+		"grouping_strategy":                 m.GroupingStrategy.ValueString(),
+		"max_entries_to_build":              m.MaxEntriesToBuild.ValueInt64(),
+		"max_entries_to_merge":              m.MaxEntriesToMerge.ValueInt64(),
+		"merge_method":                      m.MergeMethod.ValueString(),
+		"min_entries_to_merge":              m.MinEntriesToMerge.ValueInt64(),
+		"min_entries_to_merge_wait_minutes": m.MinEntriesToMergeWaitMinutes.ValueInt64(),
+	}
+}
+
+// setFromRule stores the parameters of the merge_queue rule in the model.
+func (m *rulesetMergeQueueResourceModel) setFromRule(rule *githubclient.RulesetRule) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	var params github.MergeQueueRuleParameters
+	for name, value := range map[string]any{
+		"check_response_timeout_minutes":    &params.CheckResponseTimeoutMinutes,
+		"grouping_strategy":                 &params.GroupingStrategy,
+		"max_entries_to_build":              &params.MaxEntriesToBuild,
+		"max_entries_to_merge":              &params.MaxEntriesToMerge,
+		"merge_method":                      &params.MergeMethod,
+		"min_entries_to_merge":              &params.MinEntriesToMerge,
+		"min_entries_to_merge_wait_minutes": &params.MinEntriesToMergeWaitMinutes,
+	} {
+		if _, err := rule.Parameter(name, value); err != nil {
+			diags.AddError("Error reading a ruleset", err.Error())
+			return diags
+		}
+	}
+
+	m.CheckResponseTimeoutMinutes = types.Int64Value(int64(params.CheckResponseTimeoutMinutes))
+	m.GroupingStrategy = types.StringValue(string(params.GroupingStrategy))
+	m.MaxEntriesToBuild = types.Int64Value(int64(params.MaxEntriesToBuild))
+	m.MaxEntriesToMerge = types.Int64Value(int64(params.MaxEntriesToMerge))
+	m.MergeMethod = types.StringValue(string(params.MergeMethod))
+	m.MinEntriesToMerge = types.Int64Value(int64(params.MinEntriesToMerge))
+	m.MinEntriesToMergeWaitMinutes = types.Int64Value(int64(params.MinEntriesToMergeWaitMinutes))
+	return diags
+}
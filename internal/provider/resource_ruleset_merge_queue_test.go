@@ -0,0 +1,256 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+const mergeQueueRuleset = `{"id": 123, "rules": [{"type": "merge_queue", "parameters": {"check_response_timeout_minutes": 60, "grouping_strategy": "ALLGREEN", "max_entries_to_build": 5, "max_entries_to_merge": 5, "merge_method": "MERGE", "min_entries_to_merge": 1, "min_entries_to_merge_wait_minutes": 5, "future_param": true}}]}`
+
+func TestSetRuleParameters(t *testing.T) {
+	params := map[string]any{"grouping_strategy": "HEADGREEN", "max_entries_to_build": 10, "merge_method": "SQUASH"}
+
+	tests := []struct {
+		name    string
+		ruleset string
+		want    string
+	}{
+		{
+			name:    "updates the rule",
+			ruleset: mergeQueueRuleset,
+			want:    `[{"parameters":{"check_response_timeout_minutes":60,"future_param":true,"grouping_strategy":"HEADGREEN","max_entries_to_build":10,"max_entries_to_merge":5,"merge_method":"SQUASH","min_entries_to_merge":1,"min_entries_to_merge_wait_minutes":5},"type":"merge_queue"}]`,
+		},
+		{
+			name:    "adds the rule",
+			ruleset: `{"rules": [{"type": "deletion"}]}`,
+			want:    `[{"type":"deletion"},{"parameters":{"grouping_strategy":"HEADGREEN","max_entries_to_build":10,"merge_method":"SQUASH"},"type":"merge_queue"}]`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ruleset := decodeRuleset(t, test.ruleset)
+			if err := setRuleParameters(ruleset, "merge_queue", params); err != nil {
+				t.Fatalf("setRuleParameters failed: %v", err)
+			}
+			data, _ := json.Marshal(ruleset)
+			if got := rulesJSON(t, data); got != test.want {
+				t.Errorf("Expected rules %s, got %s", test.want, got)
+			}
+		})
+	}
+}
+
+func TestMergeQueueReadDrift(t *testing.T) {
+	state := rulesetMergeQueueResourceModel{
+		rulesetTargetModel: rulesetTargetModel{
+			Repository: types.StringValue("repo"),
+			RulesetID:  types.StringValue("123"),
+			ID:         types.StringValue("owner/repo:123"),
+		},
+		CheckResponseTimeoutMinutes:  types.Int64Value(60),
+		GroupingStrategy:             types.StringValue("ALLGREEN"),
+		MaxEntriesToBuild:            types.Int64Value(5),
+		MaxEntriesToMerge:            types.Int64Value(5),
+		MergeMethod:                  types.StringValue("SQUASH"),
+		MinEntriesToMerge:            types.Int64Value(1),
+		MinEntriesToMergeWaitMinutes: types.Int64Value(5),
+	}
+
+	tests := []struct {
+		name       string
+		ruleset    string
+		driftMode  string
+		wantKept   bool
+		wantMethod string
+		wantPut    bool
+	}{
+		{"restore changed method", mergeQueueRuleset, driftModeRestore, true, "SQUASH", true},
+		{"report changed method", mergeQueueRuleset, driftModeReport, true, "MERGE", false},
+		{"ignore changed method", mergeQueueRuleset, driftModeIgnore, true, "SQUASH", false},
+		{"report removed rule", `{"id": 123, "rules": []}`, driftModeReport, false, "", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client, puts := newRulesetServer(t, test.ruleset)
+			r := &rulesetMergeQueueResource{client: client, defaultDriftMode: test.driftMode}
+
+			got := state
+			found, diags := r.refresh(context.Background(), &got, fakePrivateState{})
+			if diags.HasError() {
+				t.Fatalf("refresh failed: %v", diags)
+			}
+			if found != test.wantKept {
+				t.Fatalf("Expected the resource to be kept %v, got %v", test.wantKept, found)
+			}
+			if found && got.MergeMethod.ValueString() != test.wantMethod {
+				t.Errorf("Expected merge_method %s, got %s", test.wantMethod, got.MergeMethod)
+			}
+			if (len(*puts) > 0) != test.wantPut {
+				t.Errorf("Expected PUT %v, got %d PUTs", test.wantPut, len(*puts))
+			}
+		})
+	}
+}
+
+func TestMergeQueueReadAfterImport(t *testing.T) {
+	client, puts := newRulesetServer(t, mergeQueueRuleset)
+	r := &rulesetMergeQueueResource{client: client}
+
+	state := rulesetMergeQueueResourceModel{
+		rulesetTargetModel: rulesetTargetModel{Repository: types.StringValue("repo"), RulesetID: types.StringValue("123")},
+	}
+	private := fakePrivateState{}
+	if _, diags := r.refresh(context.Background(), &state, private); diags.HasError() {
+		t.Fatalf("refresh failed: %v", diags)
+	}
+	if state.MergeMethod.ValueString() != "MERGE" || state.MaxEntriesToBuild.ValueInt64() != 5 || state.GroupingStrategy.ValueString() != "ALLGREEN" {
+		t.Errorf("Expected the rule's parameters to be adopted, got %+v", state)
+	}
+	if len(*puts) != 0 {
+		t.Errorf("Expected no PUT, got %d", len(*puts))
+	}
+	if previous, _ := getPreviousRule(context.Background(), private); previous == nil || !previous.Exists {
+		t.Errorf("Expected the imported rule to be recorded, got %+v", previous)
+	}
+}
+
+func TestMergeQueueValidateConfig(t *testing.T) {
+	ctx := context.Background()
+	r := &rulesetMergeQueueResource{}
+
+	var schemaResp resource.SchemaResponse
+	r.Schema(ctx, resource.SchemaRequest{}, &schemaResp)
+
+	tests := []struct {
+		name       string
+		minEntries types.Int64
+		maxEntries types.Int64
+		wantErrors bool
+	}{
+		{"defaults", types.Int64Null(), types.Int64Null(), false},
+		{"min equal to max", types.Int64Value(3), types.Int64Value(3), false},
+		{"min above max", types.Int64Value(6), types.Int64Value(5), true},
+		{"min above default max", types.Int64Value(6), types.Int64Null(), true},
+		{"default min above max", types.Int64Null(), types.Int64Value(0), true},
+		{"unknown max", types.Int64Value(6), types.Int64Unknown(), false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			model := rulesetMergeQueueResourceModel{
+				rulesetTargetModel: rulesetTargetModel{Repository: types.StringValue("repo"), RulesetID: types.StringValue("123")},
+				MinEntriesToMerge:  test.minEntries,
+				MaxEntriesToMerge:  test.maxEntries,
+			}
+
+			config := tfsdk.Config{
+				Schema: schemaResp.Schema,
+				Raw:    tftypes.NewValue(schemaResp.Schema.Type().TerraformType(ctx), nil),
+			}
+			state := tfsdk.State(config)
+			if diags := state.Set(ctx, model); diags.HasError() {
+				t.Fatal(diags)
+			}
+			config.Raw = state.Raw
+
+			resp := &resource.ValidateConfigResponse{}
+			r.ValidateConfig(ctx, resource.ValidateConfigRequest{Config: config}, resp)
+			if resp.Diagnostics.HasError() != test.wantErrors {
+				t.Errorf("Expected errors %v, got %v", test.wantErrors, resp.Diagnostics)
+			}
+		})
+	}
+}
+
+func TestMergeQueueDestroy(t *testing.T) {
+	tests := []struct {
+		name      string
+		ruleset   string
+		onDestroy types.String
+		want      string
+	}{
+		{
+			name:      "restores the previous rule",
+			ruleset:   mergeQueueRuleset,
+			onDestroy: types.StringNull(),
+			want:      `[{"parameters":{"check_response_timeout_minutes":60,"future_param":true,"grouping_strategy":"ALLGREEN","max_entries_to_build":5,"max_entries_to_merge":5,"merge_method":"MERGE","min_entries_to_merge":1,"min_entries_to_merge_wait_minutes":5},"type":"merge_queue"}]`,
+		},
+		{
+			name:      "removes a rule the ruleset did not have",
+			ruleset:   `{"id": 123, "rules": [{"type": "deletion"}]}`,
+			onDestroy: types.StringValue(onDestroyRestorePrevious),
+			want:      `[{"type":"deletion"}]`,
+		},
+		{
+			name:      "leave_as_is",
+			ruleset:   `{"id": 123, "rules": [{"type": "deletion"}]}`,
+			onDestroy: types.StringValue(onDestroyLeaveAsIs),
+			want:      `[{"type":"deletion"},{"parameters":{"check_response_timeout_minutes":30,"grouping_strategy":"HEADGREEN","max_entries_to_build":10,"max_entries_to_merge":5,"merge_method":"SQUASH","min_entries_to_merge":2,"min_entries_to_merge_wait_minutes":10},"type":"merge_queue"}]`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client, puts := newRulesetServer(t, test.ruleset)
+			r := &rulesetMergeQueueResource{client: client}
+
+			ctx := context.Background()
+			plan := rulesetMergeQueueResourceModel{
+				rulesetTargetModel:           rulesetTargetModel{Repository: types.StringValue("repo"), RulesetID: types.StringValue("123")},
+				CheckResponseTimeoutMinutes:  types.Int64Value(30),
+				GroupingStrategy:             types.StringValue("HEADGREEN"),
+				MaxEntriesToBuild:            types.Int64Value(10),
+				MaxEntriesToMerge:            types.Int64Value(5),
+				MergeMethod:                  types.StringValue("SQUASH"),
+				MinEntriesToMerge:            types.Int64Value(2),
+				MinEntriesToMergeWaitMinutes: types.Int64Value(10),
+				OnDestroy:                    test.onDestroy,
+			}
+			previous, diags := r.upsert(ctx, &plan)
+			if diags.HasError() {
+				t.Fatalf("upsert failed: %v", diags)
+			}
+			private := fakePrivateState{}
+			if diags := setPreviousRule(ctx, private, previous, mergeQueueParameters); diags.HasError() {
+				t.Fatal(diags)
+			}
+			if diags := r.destroy(ctx, &plan, private); diags.HasError() {
+				t.Fatalf("destroy failed: %v", diags)
+			}
+			if got := rulesJSON(t, (*puts)[len(*puts)-1]); got != test.want {
+				t.Errorf("Expected rules %s, got %s", test.want, got)
+			}
+		})
+	}
+}
+
+func TestMergeQueueDestroyWithoutPrevious(t *testing.T) {
+	client, puts := newRulesetServer(t, mergeQueueRuleset)
+	r := &rulesetMergeQueueResource{client: client}
+
+	state := rulesetMergeQueueResourceModel{
+		rulesetTargetModel: rulesetTargetModel{
+			Repository: types.StringValue("repo"),
+			RulesetID:  types.StringValue("123"),
+			ID:         types.StringValue("owner/repo:123"),
+		},
+	}
+	diags := r.destroy(context.Background(), &state, fakePrivateState{})
+	if diags.HasError() || diags.WarningsCount() != 1 {
+		t.Errorf("Expected a single warning, got %v", diags)
+	}
+	if len(*puts) != 1 {
+		t.Fatalf("Expected 1 PUT, got %d", len(*puts))
+	}
+	if got := rulesJSON(t, (*puts)[0]); got != `[]` {
+		t.Errorf("Expected the rule to be removed, got %s", got)
+	}
+}
@@ -0,0 +1,408 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/google/go-github/v74/github"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/knowledge-work/terraform-provider-kw-github/internal/githubclient"
+)
+
+var (
+	patternRuleTypes = []string{
+		string(github.RulesetRuleTypeCommitMessagePattern),
+		string(github.RulesetRuleTypeCommitAuthorEmailPattern),
+		string(github.RulesetRuleTypeCommitterEmailPattern),
+		string(github.RulesetRuleTypeBranchNamePattern),
+		string(github.RulesetRuleTypeTagNamePattern),
+	}
+	patternRuleOperators = []string{
+		string(github.PatternRuleOperatorStartsWith),
+		string(github.PatternRuleOperatorEndsWith),
+		string(github.PatternRuleOperatorContains),
+		string(github.PatternRuleOperatorRegex),
+	}
+)
+
+func NewRulesetPatternRuleResource() resource.Resource {
+	return &rulesetPatternRuleResource{}
+}
+
+type rulesetPatternRuleResource struct {
+	client           *githubclient.Client
+	defaultDriftMode string
+}
+
+type rulesetPatternRuleResourceModel struct {
+	rulesetTargetModel
+	Type      types.String `tfsdk:"type"`
+	Operator  types.String `tfsdk:"operator"`
+	Pattern   types.String `tfsdk:"pattern"`
+	Negate    types.Bool   `tfsdk:"negate"`
+	Name      types.String `tfsdk:"name"`
+	DriftMode types.String `tfsdk:"drift_mode"`
+}
+
+func (r *rulesetPatternRuleResource) Metadata(
+	_ context.Context,
+	req resource.MetadataRequest,
+	resp *resource.MetadataResponse,
+) {
+	resp.TypeName = req.ProviderTypeName + "_ruleset_pattern_rule"
+}
+
+func (r *rulesetPatternRuleResource) Schema(
+	_ context.Context,
+	_ resource.SchemaRequest,
+	resp *resource.SchemaResponse,
+) {
+	resp.Schema = schema.Schema{
+		Description: "Manages a commit metadata or ref name pattern rule of a repository ruleset. Other rules are left untouched. Creating the resource fails if the ruleset already has a rule of the type; import it instead.",
+		Attributes: rulesetTargetAttributes(map[string]schema.Attribute{
+			"type": schema.StringAttribute{
+				Required:    true,
+				Description: "The type of the rule. Valid values are: 'commit_message_pattern', 'commit_author_email_pattern', 'committer_email_pattern', 'branch_name_pattern', 'tag_name_pattern'.",
+				Validators: []validator.String{
+					stringvalidator.OneOf(patternRuleTypes...),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"operator": schema.StringAttribute{
+				Required:    true,
+				Description: "How pattern is matched. Valid values are: 'starts_with', 'ends_with', 'contains', 'regex'.",
+				Validators: []validator.String{
+					stringvalidator.OneOf(patternRuleOperators...),
+				},
+			},
+			"pattern": schema.StringAttribute{
+				Required:    true,
+				Description: "The pattern to match. Regular expressions use RE2 syntax, like GitHub, and are validated during plan.",
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+			},
+			"negate": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+				Description: "If true, the rule fails when the pattern matches. Defaults to false.",
+			},
+			"name": schema.StringAttribute{
+				Optional:    true,
+				Description: "How the rule appears to users.",
+			},
+			"drift_mode": driftModeAttribute("the rule was changed or removed"),
+		}),
+	}
+}
+
+// ValidateConfig compiles regular expressions, so that mistakes are caught
+// during plan instead of by the GitHub API.
+func (r *rulesetPatternRuleResource) ValidateConfig(
+	ctx context.Context,
+	req resource.ValidateConfigRequest,
+	resp *resource.ValidateConfigResponse,
+) {
+	var config rulesetPatternRuleResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() || config.Operator.ValueString() != string(github.PatternRuleOperatorRegex) {
+		return
+	}
+	if config.Pattern.IsNull() || config.Pattern.IsUnknown() {
+		return
+	}
+
+	if _, err := regexp.Compile(config.Pattern.ValueString()); err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("pattern"),
+			"Invalid regular expression",
+			fmt.Sprintf("pattern is not a valid regular expression: %v", err),
+		)
+	}
+}
+
+func (r *rulesetPatternRuleResource) Configure(
+	_ context.Context,
+	req resource.ConfigureRequest,
+	resp *resource.ConfigureResponse,
+) {
+	if req.ProviderData == nil {
+		return
+	}
+	data := req.ProviderData.(*providerData)
+	r.client = data.client
+	r.defaultDriftMode = data.driftMode
+}
+
+func (r *rulesetPatternRuleResource) Create(
+	ctx context.Context,
+	req resource.CreateRequest,
+	resp *resource.CreateResponse,
+) {
+	var plan rulesetPatternRuleResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.upsert(ctx, &plan, addPatternRule)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+}
+
+func (r *rulesetPatternRuleResource) Read(
+	ctx context.Context,
+	req resource.ReadRequest,
+	resp *resource.ReadResponse,
+) {
+	var state rulesetPatternRuleResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	found, diags := r.refresh(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if !found {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// refresh updates state from GitHub and handles drift of the rule according
+// to the drift mode. It returns false if the ruleset is gone, or if the rule
+// was removed and the drift mode lets the plan add it again.
+func (r *rulesetPatternRuleResource) refresh(
+	ctx context.Context,
+	state *rulesetPatternRuleResourceModel,
+) (bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	key, err := state.key(r.client.Owner)
+	if err != nil {
+		diags.AddError("Invalid ruleset", err.Error())
+		return false, diags
+	}
+
+	ruleset, diags := getRulesetTarget(ctx, r.client, key)
+	if ruleset == nil {
+		return false, diags
+	}
+
+	imported := state.ID.IsNull()
+	state.ID = types.StringValue(rulesetTargetID(key, state.Type.ValueString()))
+
+	rule := ruleset.Rule(state.Type.ValueString())
+	desired := state.parameters()
+
+	if imported {
+		if rule == nil {
+			diags.AddError(
+				"Pattern rule not found",
+				fmt.Sprintf("Ruleset %s has no %s rule.", key, state.Type.ValueString()),
+			)
+			return false, diags
+		}
+		diags.Append(state.setFromRule(rule)...)
+	} else if drifted, err := parametersDrifted(rule, desired); err != nil {
+		diags.AddError("Error reading a ruleset", err.Error())
+	} else if drifted {
+		useActual, driftDiags := handleRuleDrift(
+			ctx, r.client, key, resolveDriftMode(state.DriftMode, r.defaultDriftMode),
+			func(ruleset *githubclient.Ruleset) error {
+				return setRuleParameters(ruleset, state.Type.ValueString(), desired)
+			},
+		)
+		diags.Append(driftDiags...)
+		if useActual {
+			if rule == nil {
+				// The rule was removed; let the plan add it again.
+				return false, diags
+			}
+			diags.Append(state.setFromRule(rule)...)
+		}
+	}
+	return true, diags
+}
+
+func (r *rulesetPatternRuleResource) Update(
+	ctx context.Context,
+	req resource.UpdateRequest,
+	resp *resource.UpdateResponse,
+) {
+	var plan rulesetPatternRuleResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.upsert(ctx, &plan, setRuleParameters)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+}
+
+// Delete removes the pattern rule from the ruleset. The rule is either one
+// this resource added or one that was imported; Create never takes over an
+// existing rule.
+func (r *rulesetPatternRuleResource) Delete(
+	ctx context.Context,
+	req resource.DeleteRequest,
+	resp *resource.DeleteResponse,
+) {
+	var state rulesetPatternRuleResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.destroy(ctx, &state)...)
+}
+
+// destroy removes the rule from the ruleset, if the ruleset still exists.
+func (r *rulesetPatternRuleResource) destroy(
+	ctx context.Context,
+	state *rulesetPatternRuleResourceModel,
+) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	key, err := state.key(r.client.Owner)
+	if err != nil {
+		diags.AddError("Invalid ruleset", err.Error())
+		return diags
+	}
+
+	_, err = r.client.ModifyRuleset(ctx, key, func(ruleset *githubclient.Ruleset) error {
+		ruleset.RemoveRule(state.Type.ValueString())
+		return nil
+	})
+	if isRulesetGone(err) {
+		// Nothing left to remove the rule from.
+		return diags
+	}
+	if err != nil {
+		diags.AddError(rulesetWriteErrorDiagnostic("Error removing a pattern rule", err))
+	}
+	return diags
+}
+
+func (r *rulesetPatternRuleResource) ImportState(
+	ctx context.Context,
+	req resource.ImportStateRequest,
+	resp *resource.ImportStateResponse,
+) {
+	fields, ok := importRulesetTarget(ctx, req.ID, resp, "type")
+	if !ok {
+		return
+	}
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("type"), fields[0])...)
+}
+
+// upsert writes the rule in plan with apply, which is addPatternRule on create
+// and setRuleParameters on update.
+func (r *rulesetPatternRuleResource) upsert(
+	ctx context.Context,
+	plan *rulesetPatternRuleResourceModel,
+	apply func(ruleset *githubclient.Ruleset, ruleType string, params map[string]any) error,
+) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	key, err := plan.key(r.client.Owner)
+	if err != nil {
+		diags.AddError("Invalid ruleset", err.Error())
+		return diags
+	}
+
+	ruleType := plan.Type.ValueString()
+	params := plan.parameters()
+	_, err = r.client.ModifyRuleset(ctx, key, func(ruleset *githubclient.Ruleset) error {
+		return apply(ruleset, ruleType, params)
+	})
+	if errors.Is(err, errRulesetEntryExists) {
+		diags.AddError(entryExistsDiagnostic(key, "a "+ruleType+" rule", rulesetTargetID(key, ruleType)))
+		return diags
+	}
+	if err != nil {
+		diags.AddError(rulesetWriteErrorDiagnostic("Error updating ruleset", err))
+		return diags
+	}
+
+	plan.ID = types.StringValue(rulesetTargetID(key, ruleType))
+	return diags
+}
+
+// addPatternRule adds a rule of ruleType with params to the ruleset. It
+// returns errRulesetEntryExists if the ruleset already has a rule of that
+// type.
+func addPatternRule(ruleset *githubclient.Ruleset, ruleType string, params map[string]any) error {
+	if ruleset.Rule(ruleType) != nil {
+		return errRulesetEntryExists
+	}
+	return setRuleParameters(ruleset, ruleType, params)
+}
+
+// parameters returns the pattern rule parameters of the model, keyed by their
+// API name. name is only managed when it is set.
+func (m *rulesetPatternRuleResourceModel) parameters() map[string]any {
+	params := map[string]any{
+		"operator": m.Operator.ValueString(),
+		"pattern":  m.Pattern.ValueString(),
+		"negate":   m.Negate.ValueBool(),
+	}
+	if !m.Name.IsNull() {
+		params["name"] = m.Name.ValueString()
+	}
+	return params
+}
+
+// setFromRule stores the parameters of the pattern rule in the model. name is
+// only stored when it is managed, even after import, so that an unset name
+// never shows a diff.
+func (m *rulesetPatternRuleResourceModel) setFromRule(rule *githubclient.RulesetRule) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	var params github.PatternRuleParameters
+	for name, value := range map[string]any{
+		"operator": &params.Operator,
+		"pattern":  &params.Pattern,
+		"negate":   &params.Negate,
+		"name":     &params.Name,
+	} {
+		if _, err := rule.Parameter(name, value); err != nil {
+			diags.AddError("Error reading a ruleset", err.Error())
+			return diags
+		}
+	}
+
+	m.Operator = types.StringValue(string(params.Operator))
+	m.Pattern = types.StringValue(params.Pattern)
+	m.Negate = types.BoolValue(params.GetNegate())
+	if !m.Name.IsNull() {
+		m.Name = types.StringPointerValue(params.Name)
+	}
+	return diags
+}
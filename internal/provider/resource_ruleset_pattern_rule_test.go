@@ -0,0 +1,196 @@
+package provider
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/knowledge-work/terraform-provider-kw-github/internal/githubclient"
+)
+
+const patternRuleset = `{"id": 123, "rules": [{"type": "deletion"}, {"type": "commit_message_pattern", "parameters": {"name": "Ticket", "operator": "starts_with", "pattern": "KW-"}}]}`
+
+func TestPatternRuleValidateConfig(t *testing.T) {
+	ctx := context.Background()
+	r := &rulesetPatternRuleResource{}
+
+	var schemaResp resource.SchemaResponse
+	r.Schema(ctx, resource.SchemaRequest{}, &schemaResp)
+
+	tests := []struct {
+		operator   string
+		pattern    string
+		wantErrors bool
+	}{
+		{"regex", `^KW-\d+`, false},
+		{"regex", `^KW-(\d+`, true},
+		{"starts_with", `KW-(`, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.operator+" "+test.pattern, func(t *testing.T) {
+			model := rulesetPatternRuleResourceModel{
+				rulesetTargetModel: rulesetTargetModel{Repository: types.StringValue("repo"), RulesetID: types.StringValue("123")},
+				Type:               types.StringValue("commit_message_pattern"),
+				Operator:           types.StringValue(test.operator),
+				Pattern:            types.StringValue(test.pattern),
+			}
+
+			config := tfsdk.Config{
+				Schema: schemaResp.Schema,
+				Raw:    tftypes.NewValue(schemaResp.Schema.Type().TerraformType(ctx), nil),
+			}
+			state := tfsdk.State(config)
+			if diags := state.Set(ctx, model); diags.HasError() {
+				t.Fatal(diags)
+			}
+			config.Raw = state.Raw
+
+			resp := &resource.ValidateConfigResponse{}
+			r.ValidateConfig(ctx, resource.ValidateConfigRequest{Config: config}, resp)
+			if resp.Diagnostics.HasError() != test.wantErrors {
+				t.Errorf("Expected errors %v, got %v", test.wantErrors, resp.Diagnostics)
+			}
+		})
+	}
+}
+
+func TestPatternRuleUpsert(t *testing.T) {
+	tests := []struct {
+		name      string
+		ruleset   string
+		apply     func(*githubclient.Ruleset, string, map[string]any) error
+		wantError bool
+		want      string
+	}{
+		{
+			name:    "create adds the rule",
+			ruleset: `{"id": 123, "rules": [{"type": "deletion"}]}`,
+			apply:   addPatternRule,
+			want:    `[{"type":"deletion"},{"parameters":{"negate":false,"operator":"regex","pattern":"^KW-\\d+"},"type":"commit_message_pattern"}]`,
+		},
+		{
+			name:      "create rejects an existing rule",
+			ruleset:   patternRuleset,
+			apply:     addPatternRule,
+			wantError: true,
+		},
+		{
+			name:    "update replaces the managed parameters",
+			ruleset: patternRuleset,
+			apply:   setRuleParameters,
+			want:    `[{"type":"deletion"},{"parameters":{"name":"Ticket","negate":false,"operator":"regex","pattern":"^KW-\\d+"},"type":"commit_message_pattern"}]`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client, puts := newRulesetServer(t, test.ruleset)
+			r := &rulesetPatternRuleResource{client: client}
+
+			plan := rulesetPatternRuleResourceModel{
+				rulesetTargetModel: rulesetTargetModel{Repository: types.StringValue("repo"), RulesetID: types.StringValue("123")},
+				Type:               types.StringValue("commit_message_pattern"),
+				Operator:           types.StringValue("regex"),
+				Pattern:            types.StringValue(`^KW-\d+`),
+				Negate:             types.BoolValue(false),
+			}
+
+			diags := r.upsert(context.Background(), &plan, test.apply)
+			if diags.HasError() != test.wantError {
+				t.Fatalf("Expected error %v, got %v", test.wantError, diags)
+			}
+			if test.wantError {
+				if detail := diags.Errors()[0].Detail(); !strings.Contains(detail, `terraform import using the ID "owner/repo:123:commit_message_pattern"`) {
+					t.Errorf("Expected the error to point to terraform import, got %q", detail)
+				}
+				if len(*puts) != 0 {
+					t.Errorf("Expected no PUT, got %d", len(*puts))
+				}
+				return
+			}
+			if len(*puts) != 1 {
+				t.Fatalf("Expected 1 PUT, got %d", len(*puts))
+			}
+			if got := rulesJSON(t, (*puts)[0]); got != test.want {
+				t.Errorf("Expected rules %s, got %s", test.want, got)
+			}
+		})
+	}
+}
+
+func TestPatternRuleReadDrift(t *testing.T) {
+	tests := []struct {
+		name        string
+		driftMode   string
+		pattern     string
+		wantPattern string
+		wantPut     bool
+	}{
+		{"restore changed pattern", driftModeRestore, "TICKET-", "TICKET-", true},
+		{"report changed pattern", driftModeReport, "TICKET-", "KW-", false},
+		{"ignore changed pattern", driftModeIgnore, "TICKET-", "TICKET-", false},
+		// GitHub leaves out negate when it is false.
+		{"no drift", driftModeRestore, "KW-", "KW-", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client, puts := newRulesetServer(t, patternRuleset)
+			r := &rulesetPatternRuleResource{client: client, defaultDriftMode: test.driftMode}
+
+			state := rulesetPatternRuleResourceModel{
+				rulesetTargetModel: rulesetTargetModel{
+					Repository: types.StringValue("repo"),
+					RulesetID:  types.StringValue("123"),
+					ID:         types.StringValue("owner/repo:123:commit_message_pattern"),
+				},
+				Type:     types.StringValue("commit_message_pattern"),
+				Operator: types.StringValue("starts_with"),
+				Pattern:  types.StringValue(test.pattern),
+				Negate:   types.BoolValue(false),
+			}
+
+			found, diags := r.refresh(context.Background(), &state)
+			if diags.HasError() {
+				t.Fatalf("refresh failed: %v", diags)
+			}
+			if !found {
+				t.Fatal("Expected the resource to be kept")
+			}
+			if state.Pattern.ValueString() != test.wantPattern {
+				t.Errorf("Expected pattern %s, got %s", test.wantPattern, state.Pattern)
+			}
+			if !state.Name.IsNull() {
+				t.Errorf("Expected the unmanaged name to stay null, got %s", state.Name)
+			}
+			if (len(*puts) > 0) != test.wantPut {
+				t.Errorf("Expected PUT %v, got %d PUTs", test.wantPut, len(*puts))
+			}
+		})
+	}
+}
+
+func TestPatternRuleReadAfterImport(t *testing.T) {
+	client, _ := newRulesetServer(t, patternRuleset)
+	r := &rulesetPatternRuleResource{client: client}
+
+	state := rulesetPatternRuleResourceModel{
+		rulesetTargetModel: rulesetTargetModel{Repository: types.StringValue("repo"), RulesetID: types.StringValue("123")},
+		Type:               types.StringValue("commit_message_pattern"),
+	}
+	if _, diags := r.refresh(context.Background(), &state); diags.HasError() {
+		t.Fatalf("refresh failed: %v", diags)
+	}
+
+	if state.Pattern.ValueString() != "KW-" || state.Operator.ValueString() != "starts_with" || !state.Name.IsNull() {
+		t.Errorf("Expected the rule's parameters to be adopted, got %+v", state)
+	}
+	if state.ID.ValueString() != "owner/repo:123:commit_message_pattern" {
+		t.Errorf("Unexpected ID %s", state.ID)
+	}
+}
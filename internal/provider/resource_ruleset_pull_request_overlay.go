@@ -0,0 +1,556 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/google/go-github/v74/github"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/knowledge-work/terraform-provider-kw-github/internal/githubclient"
+)
+
+func NewRulesetPullRequestOverlayResource() resource.Resource {
+	return &rulesetPullRequestOverlayResource{}
+}
+
+type rulesetPullRequestOverlayResource struct {
+	client           *githubclient.Client
+	defaultDriftMode string
+}
+
+type rulesetPullRequestOverlayResourceModel struct {
+	rulesetTargetModel
+	DismissStaleReviewsOnPush         types.Bool   `tfsdk:"dismiss_stale_reviews_on_push"`
+	RequireCodeOwnerReview            types.Bool   `tfsdk:"require_code_owner_review"`
+	RequireLastPushApproval           types.Bool   `tfsdk:"require_last_push_approval"`
+	RequiredApprovingReviewCount      types.Int64  `tfsdk:"required_approving_review_count"`
+	RequiredReviewThreadResolution    types.Bool   `tfsdk:"required_review_thread_resolution"`
+	AutomaticCopilotCodeReviewEnabled types.Bool   `tfsdk:"automatic_copilot_code_review_enabled"`
+	RequiredReviewers                 types.List   `tfsdk:"required_reviewers"`
+	DriftMode                         types.String `tfsdk:"drift_mode"`
+	OnDestroy                         types.String `tfsdk:"on_destroy"`
+}
+
+type requiredReviewerModel struct {
+	MinimumApprovals types.Int64  `tfsdk:"minimum_approvals"`
+	FilePatterns     []string     `tfsdk:"file_patterns"`
+	ReviewerID       types.Int64  `tfsdk:"reviewer_id"`
+	ReviewerType     types.String `tfsdk:"reviewer_type"`
+}
+
+var requiredReviewerAttrTypes = map[string]attr.Type{
+	"minimum_approvals": types.Int64Type,
+	"file_patterns":     types.ListType{ElemType: types.StringType},
+	"reviewer_id":       types.Int64Type,
+	"reviewer_type":     types.StringType,
+}
+
+// pullRequestOverlayParameters are the pull_request rule parameters the
+// resource can manage.
+var pullRequestOverlayParameters = []string{
+	"dismiss_stale_reviews_on_push",
+	"require_code_owner_review",
+	"require_last_push_approval",
+	"required_approving_review_count",
+	"required_review_thread_resolution",
+	"automatic_copilot_code_review_enabled",
+	"required_reviewers",
+}
+
+// errNoPullRequestRule is returned when the ruleset has no pull_request rule
+// whose parameters could be set.
+var errNoPullRequestRule = errors.New("the ruleset has no pull_request rule")
+
+// rulesetRequiredReviewer is an entry of the pull_request rule's
+// required_reviewers parameter, which go-github does not support yet.
+type rulesetRequiredReviewer struct {
+	MinimumApprovals int64    `json:"minimum_approvals"`
+	FilePatterns     []string `json:"file_patterns"`
+	Reviewer         struct {
+		ID   int64  `json:"id"`
+		Type string `json:"type"`
+	} `json:"reviewer"`
+}
+
+func (r *rulesetPullRequestOverlayResource) Metadata(
+	_ context.Context,
+	req resource.MetadataRequest,
+	resp *resource.MetadataResponse,
+) {
+	resp.TypeName = req.ProviderTypeName + "_ruleset_pull_request_overlay"
+}
+
+func (r *rulesetPullRequestOverlayResource) Schema(
+	_ context.Context,
+	_ resource.SchemaRequest,
+	resp *resource.SchemaResponse,
+) {
+	resp.Schema = schema.Schema{
+		Description: "Manages selected parameters of a repository ruleset's pull_request rule. " +
+			"Parameters that are not set are left untouched. The ruleset must already have a pull_request rule.",
+		Attributes: rulesetTargetAttributes(map[string]schema.Attribute{
+			"dismiss_stale_reviews_on_push": schema.BoolAttribute{
+				Optional:    true,
+				Description: "New, reviewable commits pushed will dismiss previous pull request review approvals.",
+			},
+			"require_code_owner_review": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Require an approving review in pull requests that modify files that have a designated code owner.",
+			},
+			"require_last_push_approval": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Whether the most recent reviewable push must be approved by someone other than the person who pushed it.",
+			},
+			"required_approving_review_count": schema.Int64Attribute{
+				Optional:    true,
+				Description: "The number of approving reviews that are required before a pull request can be merged.",
+				Validators: []validator.Int64{
+					int64validator.Between(0, 10),
+				},
+			},
+			"required_review_thread_resolution": schema.BoolAttribute{
+				Optional:    true,
+				Description: "All conversations on code must be resolved before a pull request can be merged.",
+			},
+			"automatic_copilot_code_review_enabled": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Request a code review from Copilot automatically for new pull requests.",
+			},
+			"required_reviewers": schema.ListNestedAttribute{
+				Optional:    true,
+				Description: "Teams that must approve pull requests changing files that match the given patterns. The list replaces the ruleset's required reviewers.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"minimum_approvals": schema.Int64Attribute{
+							Required:    true,
+							Description: "The number of approvals required from the reviewer.",
+							Validators: []validator.Int64{
+								int64validator.AtLeast(0),
+							},
+						},
+						"file_patterns": schema.ListAttribute{
+							ElementType: types.StringType,
+							Required:    true,
+							Description: "File patterns (fnmatch syntax) the reviewer is required for.",
+							Validators: []validator.List{
+								listvalidator.SizeAtLeast(1),
+							},
+						},
+						"reviewer_id": schema.Int64Attribute{
+							Required:    true,
+							Description: "The ID of the reviewer.",
+						},
+						"reviewer_type": schema.StringAttribute{
+							Required:    true,
+							Description: "The type of the reviewer, e.g. 'Team'.",
+						},
+					},
+				},
+			},
+			"drift_mode": driftModeAttribute("the managed parameters were changed"),
+			"on_destroy": ruleOnDestroyAttribute("restores the managed parameters to the values they had when the resource was created or imported"),
+		}),
+	}
+}
+
+func (r *rulesetPullRequestOverlayResource) Configure(
+	_ context.Context,
+	req resource.ConfigureRequest,
+	resp *resource.ConfigureResponse,
+) {
+	if req.ProviderData == nil {
+		return
+	}
+	data := req.ProviderData.(*providerData)
+	r.client = data.client
+	r.defaultDriftMode = data.driftMode
+}
+
+func (r *rulesetPullRequestOverlayResource) Create(
+	ctx context.Context,
+	req resource.CreateRequest,
+	resp *resource.CreateResponse,
+) {
+	var plan rulesetPullRequestOverlayResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	previous, diags := r.upsert(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(setPreviousRule(ctx, resp.Private, previous, pullRequestOverlayParameters)...)
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+}
+
+func (r *rulesetPullRequestOverlayResource) Read(
+	ctx context.Context,
+	req resource.ReadRequest,
+	resp *resource.ReadResponse,
+) {
+	var state rulesetPullRequestOverlayResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	found, diags := r.refresh(ctx, &state, resp.Private)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if !found {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// refresh updates state from GitHub and handles drift of the managed
+// parameters according to the drift mode. Right after import, every
+// parameter is adopted and the rule is recorded in private for Delete. It
+// returns false if the ruleset is gone.
+func (r *rulesetPullRequestOverlayResource) refresh(
+	ctx context.Context,
+	state *rulesetPullRequestOverlayResourceModel,
+	private privateState,
+) (bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	key, err := state.key(r.client.Owner)
+	if err != nil {
+		diags.AddError("Invalid ruleset", err.Error())
+		return false, diags
+	}
+
+	ruleset, diags := getRulesetTarget(ctx, r.client, key)
+	if ruleset == nil {
+		return false, diags
+	}
+
+	// Right after import nothing is managed yet; adopt every parameter.
+	imported := state.ID.IsNull()
+	state.ID = types.StringValue(rulesetTargetID(key))
+
+	desired, paramDiags := state.parameters(ctx)
+	diags.Append(paramDiags...)
+	if diags.HasError() {
+		return false, diags
+	}
+
+	rule := ruleset.Rule(string(github.RulesetRuleTypePullRequest))
+	if imported {
+		diags.Append(state.setFromRule(ctx, rule, nil)...)
+		diags.Append(setPreviousRule(ctx, private, rule, pullRequestOverlayParameters)...)
+	} else if drifted, err := parametersDrifted(rule, desired); err != nil {
+		diags.AddError("Error reading a ruleset", err.Error())
+	} else if drifted {
+		useActual, driftDiags := handleRuleDrift(
+			ctx, r.client, key, resolveDriftMode(state.DriftMode, r.defaultDriftMode),
+			func(ruleset *githubclient.Ruleset) error {
+				return setPullRequestParameters(ruleset, desired)
+			},
+		)
+		diags.Append(driftDiags...)
+		if useActual {
+			diags.Append(state.setFromRule(ctx, rule, desired)...)
+		}
+	}
+	return true, diags
+}
+
+func (r *rulesetPullRequestOverlayResource) Update(
+	ctx context.Context,
+	req resource.UpdateRequest,
+	resp *resource.UpdateResponse,
+) {
+	var plan rulesetPullRequestOverlayResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	_, diags := r.upsert(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+}
+
+func (r *rulesetPullRequestOverlayResource) Delete(
+	ctx context.Context,
+	req resource.DeleteRequest,
+	resp *resource.DeleteResponse,
+) {
+	var state rulesetPullRequestOverlayResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.destroy(ctx, &state, req.Private)...)
+}
+
+// destroy restores the managed parameters to the values recorded in private
+// at create or import time, according to on_destroy.
+func (r *rulesetPullRequestOverlayResource) destroy(
+	ctx context.Context,
+	state *rulesetPullRequestOverlayResourceModel,
+	private privateState,
+) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	key, err := state.key(r.client.Owner)
+	if err != nil {
+		diags.AddError("Invalid ruleset", err.Error())
+		return diags
+	}
+
+	previous, diags := getPreviousRule(ctx, private)
+	if diags.HasError() {
+		return diags
+	}
+	if previous == nil && state.OnDestroy.ValueString() != onDestroyLeaveAsIs {
+		diags.AddWarning(
+			"Previous parameters unknown",
+			"The values the parameters had before Terraform managed them were not recorded, so they are left as they are.",
+		)
+		return diags
+	}
+
+	params, paramDiags := state.parameters(ctx)
+	diags.Append(paramDiags...)
+	if diags.HasError() {
+		return diags
+	}
+	managed := make([]string, 0, len(params))
+	for name := range params {
+		managed = append(managed, name)
+	}
+
+	diags.Append(destroyRule(
+		ctx, r.client, key, string(github.RulesetRuleTypePullRequest), managed, state.OnDestroy, previous,
+	)...)
+	return diags
+}
+
+func (r *rulesetPullRequestOverlayResource) ImportState(
+	ctx context.Context,
+	req resource.ImportStateRequest,
+	resp *resource.ImportStateResponse,
+) {
+	importRulesetTarget(ctx, req.ID, resp)
+}
+
+// upsert writes the parameters in plan and returns the pull_request rule as it
+// was before.
+func (r *rulesetPullRequestOverlayResource) upsert(
+	ctx context.Context,
+	plan *rulesetPullRequestOverlayResourceModel,
+) (*githubclient.RulesetRule, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	key, err := plan.key(r.client.Owner)
+	if err != nil {
+		diags.AddError("Invalid ruleset", err.Error())
+		return nil, diags
+	}
+
+	params, diags := plan.parameters(ctx)
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	var previous *githubclient.RulesetRule
+	_, err = r.client.ModifyRuleset(ctx, key, func(ruleset *githubclient.Ruleset) error {
+		if rule := ruleset.Rule(string(github.RulesetRuleTypePullRequest)); rule != nil {
+			previous = rule.Clone()
+		}
+		return setPullRequestParameters(ruleset, params)
+	})
+	if errors.Is(err, errNoPullRequestRule) {
+		diags.AddError(
+			"Ruleset has no pull_request rule",
+			fmt.Sprintf("Ruleset %s has no pull_request rule. Add the rule to the ruleset, e.g. with "+
+				"github_repository_ruleset, before managing its parameters with this resource.", key),
+		)
+		return nil, diags
+	}
+	if err != nil {
+		diags.AddError(rulesetWriteErrorDiagnostic("Error updating ruleset", err))
+		return nil, diags
+	}
+
+	plan.ID = types.StringValue(rulesetTargetID(key))
+	return previous, diags
+}
+
+// parameters returns the pull_request rule parameters set in the model, keyed
+// by their API name. Unset parameters are not managed and left out.
+func (m *rulesetPullRequestOverlayResourceModel) parameters(ctx context.Context) (map[string]any, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	params := make(map[string]any)
+
+	for name, value := range map[string]types.Bool{
+		"dismiss_stale_reviews_on_push":         m.DismissStaleReviewsOnPush,
+		"require_code_owner_review":             m.RequireCodeOwnerReview,
+		"require_last_push_approval":            m.RequireLastPushApproval,
+		"required_review_thread_resolution":     m.RequiredReviewThreadResolution,
+		"automatic_copilot_code_review_enabled": m.AutomaticCopilotCodeReviewEnabled,
+	} {
+		if !value.IsNull() {
+			params[name] = value.ValueBool()
+		}
+	}
+	if !m.RequiredApprovingReviewCount.IsNull() {
+		params["required_approving_review_count"] = m.RequiredApprovingReviewCount.ValueInt64()
+	}
+
+	if !m.RequiredReviewers.IsNull() {
+		var reviewers []requiredReviewerModel
+		diags.Append(m.RequiredReviewers.ElementsAs(ctx, &reviewers, false)...)
+
+		required := make([]rulesetRequiredReviewer, 0, len(reviewers))
+		for _, reviewer := range reviewers {
+			var entry rulesetRequiredReviewer
+			entry.MinimumApprovals = reviewer.MinimumApprovals.ValueInt64()
+			entry.FilePatterns = reviewer.FilePatterns
+			entry.Reviewer.ID = reviewer.ReviewerID.ValueInt64()
+			entry.Reviewer.Type = reviewer.ReviewerType.ValueString()
+			required = append(required, entry)
+		}
+		params["required_reviewers"] = required
+	}
+
+	return params, diags
+}
+
+// setFromRule stores the parameters of rule in the model. Only the parameters
+// in managed are stored, or all of them if managed is nil.
+func (m *rulesetPullRequestOverlayResourceModel) setFromRule(
+	ctx context.Context,
+	rule *githubclient.RulesetRule,
+	managed map[string]any,
+) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if rule == nil {
+		rule, _ = githubclient.NewRulesetRule(string(github.RulesetRuleTypePullRequest), nil)
+	}
+	wanted := func(name string) bool {
+		if managed == nil {
+			return true
+		}
+		_, ok := managed[name]
+		return ok
+	}
+
+	for name, value := range map[string]*types.Bool{
+		"dismiss_stale_reviews_on_push":         &m.DismissStaleReviewsOnPush,
+		"require_code_owner_review":             &m.RequireCodeOwnerReview,
+		"require_last_push_approval":            &m.RequireLastPushApproval,
+		"required_review_thread_resolution":     &m.RequiredReviewThreadResolution,
+		"automatic_copilot_code_review_enabled": &m.AutomaticCopilotCodeReviewEnabled,
+	} {
+		if !wanted(name) {
+			continue
+		}
+		var actual bool
+		if _, err := rule.Parameter(name, &actual); err != nil {
+			diags.AddError("Error reading a ruleset", err.Error())
+			return diags
+		}
+		*value = types.BoolValue(actual)
+	}
+
+	if wanted("required_approving_review_count") {
+		var actual int64
+		if _, err := rule.Parameter("required_approving_review_count", &actual); err != nil {
+			diags.AddError("Error reading a ruleset", err.Error())
+			return diags
+		}
+		m.RequiredApprovingReviewCount = types.Int64Value(actual)
+	}
+
+	if wanted("required_reviewers") {
+		var actual []rulesetRequiredReviewer
+		if _, err := rule.Parameter("required_reviewers", &actual); err != nil {
+			diags.AddError("Error reading a ruleset", err.Error())
+			return diags
+		}
+
+		reviewers := make([]requiredReviewerModel, 0, len(actual))
+		for _, entry := range actual {
+			reviewers = append(reviewers, requiredReviewerModel{
+				MinimumApprovals: types.Int64Value(entry.MinimumApprovals),
+				FilePatterns:     entry.FilePatterns,
+				ReviewerID:       types.Int64Value(entry.Reviewer.ID),
+				ReviewerType:     types.StringValue(entry.Reviewer.Type),
+			})
+		}
+		list, d := types.ListValueFrom(ctx, types.ObjectType{AttrTypes: requiredReviewerAttrTypes}, reviewers)
+		diags.Append(d...)
+		m.RequiredReviewers = list
+	}
+
+	return diags
+}
+
+// setPullRequestParameters sets params on the ruleset's pull_request rule.
+// Other parameters are kept. It returns errNoPullRequestRule if the ruleset
+// has no pull_request rule, since its other parameters are not managed here.
+func setPullRequestParameters(ruleset *githubclient.Ruleset, params map[string]any) error {
+	rule := ruleset.Rule(string(github.RulesetRuleTypePullRequest))
+	if rule == nil {
+		return errNoPullRequestRule
+	}
+
+	for name, value := range params {
+		if err := rule.SetParameter(name, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parametersDrifted reports whether any parameter in desired differs from
+// the rule. A missing parameter is compared as its zero value, since GitHub
+// leaves out e.g. false booleans; a missing rule counts as drift.
+func parametersDrifted(rule *githubclient.RulesetRule, desired map[string]any) (bool, error) {
+	if len(desired) == 0 {
+		return false, nil
+	}
+	if rule == nil {
+		return true, nil
+	}
+
+	for name, value := range desired {
+		actual := reflect.New(reflect.TypeOf(value))
+		if _, err := rule.Parameter(name, actual.Interface()); err != nil {
+			return false, err
+		}
+
+		want, _ := json.Marshal(value)
+		got, _ := json.Marshal(actual.Elem().Interface())
+		if string(want) != string(got) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
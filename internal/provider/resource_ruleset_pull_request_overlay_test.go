@@ -0,0 +1,299 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-github/v74/github"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/knowledge-work/terraform-provider-kw-github/internal/githubclient"
+)
+
+func TestPullRequestOverlayUpsertKeepsOtherParameters(t *testing.T) {
+	client, puts := newRulesetServer(t, `{"id": 123, "rules": [{"type": "pull_request", "parameters": {"allowed_merge_methods": ["squash"], "required_approving_review_count": 1, "future_param": "x"}}]}`)
+	r := &rulesetPullRequestOverlayResource{client: client}
+
+	reviewers, diags := types.ListValueFrom(context.Background(), types.ObjectType{AttrTypes: requiredReviewerAttrTypes}, []requiredReviewerModel{{
+		MinimumApprovals: types.Int64Value(1),
+		FilePatterns:     []string{"*.go"},
+		ReviewerID:       types.Int64Value(7),
+		ReviewerType:     types.StringValue("Team"),
+	}})
+	if diags.HasError() {
+		t.Fatal(diags)
+	}
+	plan := rulesetPullRequestOverlayResourceModel{
+		rulesetTargetModel:                rulesetTargetModel{Repository: types.StringValue("repo"), RulesetID: types.StringValue("123")},
+		AutomaticCopilotCodeReviewEnabled: types.BoolValue(true),
+		RequiredReviewers:                 reviewers,
+	}
+
+	if _, diags := r.upsert(context.Background(), &plan); diags.HasError() {
+		t.Fatalf("upsert failed: %v", diags)
+	}
+	if len(*puts) != 1 {
+		t.Fatalf("Expected 1 PUT, got %d", len(*puts))
+	}
+
+	var written struct {
+		Rules []struct {
+			Parameters map[string]json.RawMessage `json:"parameters"`
+		} `json:"rules"`
+	}
+	if err := json.Unmarshal((*puts)[0], &written); err != nil {
+		t.Fatal(err)
+	}
+	params := written.Rules[0].Parameters
+	want := map[string]string{
+		"allowed_merge_methods":                 `["squash"]`,
+		"required_approving_review_count":       `1`,
+		"future_param":                          `"x"`,
+		"automatic_copilot_code_review_enabled": `true`,
+		"required_reviewers":                    `[{"minimum_approvals":1,"file_patterns":["*.go"],"reviewer":{"id":7,"type":"Team"}}]`,
+	}
+	for name, value := range want {
+		if string(params[name]) != value {
+			t.Errorf("Expected %s to be %s, got %s", name, value, params[name])
+		}
+	}
+	if plan.ID.ValueString() != "owner/repo:123" {
+		t.Errorf("Unexpected ID %s", plan.ID)
+	}
+}
+
+func TestPullRequestOverlayUpsertWithoutRule(t *testing.T) {
+	client, puts := newRulesetServer(t, `{"id": 123, "rules": [{"type": "deletion"}]}`)
+	r := &rulesetPullRequestOverlayResource{client: client}
+
+	plan := rulesetPullRequestOverlayResourceModel{
+		rulesetTargetModel:           rulesetTargetModel{Repository: types.StringValue("repo"), RulesetID: types.StringValue("123")},
+		RequiredApprovingReviewCount: types.Int64Value(2),
+		RequiredReviewers:            types.ListNull(types.ObjectType{AttrTypes: requiredReviewerAttrTypes}),
+	}
+	_, diags := r.upsert(context.Background(), &plan)
+	if !diags.HasError() {
+		t.Fatal("Expected an error")
+	}
+	if got := diags.Errors()[0].Summary(); got != "Ruleset has no pull_request rule" {
+		t.Errorf("Unexpected error %q", got)
+	}
+	if len(*puts) != 0 {
+		t.Errorf("Expected no PUT, got %d", len(*puts))
+	}
+}
+
+func TestPullRequestOverlayDestroy(t *testing.T) {
+	const ruleset = `{"id": 123, "rules": [{"type": "pull_request", "parameters": {"allowed_merge_methods": ["squash"], "required_approving_review_count": 1, "require_code_owner_review": true}}]}`
+
+	tests := []struct {
+		name      string
+		onDestroy types.String
+		want      string
+	}{
+		{
+			name:      "default restores previous",
+			onDestroy: types.StringNull(),
+			want:      `[{"parameters":{"allowed_merge_methods":["squash"],"require_code_owner_review":false,"required_approving_review_count":1},"type":"pull_request"}]`,
+		},
+		{
+			name:      "restore_previous",
+			onDestroy: types.StringValue(onDestroyRestorePrevious),
+			want:      `[{"parameters":{"allowed_merge_methods":["squash"],"require_code_owner_review":false,"required_approving_review_count":1},"type":"pull_request"}]`,
+		},
+		{
+			name:      "leave_as_is",
+			onDestroy: types.StringValue(onDestroyLeaveAsIs),
+			want:      `[{"parameters":{"allowed_merge_methods":["squash"],"automatic_copilot_code_review_enabled":true,"require_code_owner_review":false,"required_approving_review_count":2},"type":"pull_request"}]`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			client, puts := newRulesetServer(t, ruleset)
+			r := &rulesetPullRequestOverlayResource{client: client}
+
+			state := rulesetPullRequestOverlayResourceModel{
+				rulesetTargetModel:                rulesetTargetModel{Repository: types.StringValue("repo"), RulesetID: types.StringValue("123")},
+				RequiredApprovingReviewCount:      types.Int64Value(2),
+				AutomaticCopilotCodeReviewEnabled: types.BoolValue(true),
+				RequiredReviewers:                 types.ListNull(types.ObjectType{AttrTypes: requiredReviewerAttrTypes}),
+				OnDestroy:                         test.onDestroy,
+			}
+			previous, diags := r.upsert(ctx, &state)
+			if diags.HasError() {
+				t.Fatalf("upsert failed: %v", diags)
+			}
+			private := fakePrivateState{}
+			setPreviousRule(ctx, private, previous, pullRequestOverlayParameters)
+
+			// An unmanaged parameter changed after create is kept.
+			if _, err := client.ModifyRuleset(ctx, githubclient.RulesetKey{Owner: "owner", Repo: "repo", ID: 123}, func(ruleset *githubclient.Ruleset) error {
+				return ruleset.Rule("pull_request").SetParameter("require_code_owner_review", false)
+			}); err != nil {
+				t.Fatal(err)
+			}
+
+			if diags := r.destroy(ctx, &state, private); diags.HasError() {
+				t.Fatalf("destroy failed: %v", diags)
+			}
+			if got := rulesJSON(t, (*puts)[len(*puts)-1]); got != test.want {
+				t.Errorf("Expected rules %s, got %s", test.want, got)
+			}
+		})
+	}
+}
+
+func TestPullRequestOverlayDestroyAfterImport(t *testing.T) {
+	ctx := context.Background()
+	client, puts := newRulesetServer(t, `{"id": 123, "rules": [{"type": "pull_request", "parameters": {"required_approving_review_count": 3}}]}`)
+	r := &rulesetPullRequestOverlayResource{client: client}
+
+	state := rulesetPullRequestOverlayResourceModel{
+		rulesetTargetModel: rulesetTargetModel{Repository: types.StringValue("repo"), RulesetID: types.StringValue("123")},
+		RequiredReviewers:  types.ListNull(types.ObjectType{AttrTypes: requiredReviewerAttrTypes}),
+	}
+	private := fakePrivateState{}
+	if _, diags := r.refresh(ctx, &state, private); diags.HasError() {
+		t.Fatalf("refresh failed: %v", diags)
+	}
+
+	state.RequiredApprovingReviewCount = types.Int64Value(5)
+	if _, diags := r.upsert(ctx, &state); diags.HasError() {
+		t.Fatalf("upsert failed: %v", diags)
+	}
+
+	if diags := r.destroy(ctx, &state, private); diags.HasError() {
+		t.Fatalf("destroy failed: %v", diags)
+	}
+	want := `[{"parameters":{"required_approving_review_count":3},"type":"pull_request"}]`
+	if got := rulesJSON(t, (*puts)[len(*puts)-1]); got != want {
+		t.Errorf("Expected rules %s, got %s", want, got)
+	}
+}
+
+func TestPullRequestOverlayDestroyWithoutPrevious(t *testing.T) {
+	client, puts := newRulesetServer(t, `{"id": 123, "rules": [{"type": "pull_request", "parameters": {"required_approving_review_count": 3}}]}`)
+	r := &rulesetPullRequestOverlayResource{client: client}
+
+	state := rulesetPullRequestOverlayResourceModel{
+		rulesetTargetModel:           rulesetTargetModel{Repository: types.StringValue("repo"), RulesetID: types.StringValue("123")},
+		RequiredApprovingReviewCount: types.Int64Value(3),
+		RequiredReviewers:            types.ListNull(types.ObjectType{AttrTypes: requiredReviewerAttrTypes}),
+	}
+	diags := r.destroy(context.Background(), &state, fakePrivateState{})
+	if diags.HasError() || diags.WarningsCount() != 1 {
+		t.Errorf("Expected a single warning, got %v", diags)
+	}
+	if len(*puts) != 0 {
+		t.Errorf("Expected no PUT, got %d", len(*puts))
+	}
+}
+
+func TestPullRequestOverlayReadDrift(t *testing.T) {
+	tests := []struct {
+		driftMode string
+		wantCount int64
+		wantPut   bool
+	}{
+		{driftModeRestore, 2, true},
+		{driftModeReport, 1, false},
+		{driftModeIgnore, 2, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.driftMode, func(t *testing.T) {
+			client, puts := newRulesetServer(t, `{"id": 123, "rules": [{"type": "pull_request", "parameters": {"required_approving_review_count": 1, "require_code_owner_review": true}}]}`)
+			r := &rulesetPullRequestOverlayResource{client: client, defaultDriftMode: test.driftMode}
+
+			state := rulesetPullRequestOverlayResourceModel{
+				rulesetTargetModel: rulesetTargetModel{
+					Repository: types.StringValue("repo"),
+					RulesetID:  types.StringValue("123"),
+					ID:         types.StringValue("owner/repo:123"),
+				},
+				RequiredApprovingReviewCount: types.Int64Value(2),
+				RequiredReviewers:            types.ListNull(types.ObjectType{AttrTypes: requiredReviewerAttrTypes}),
+			}
+
+			found, diags := r.refresh(context.Background(), &state, fakePrivateState{})
+			if diags.HasError() {
+				t.Fatalf("refresh failed: %v", diags)
+			}
+			if !found {
+				t.Fatal("Expected the resource to be kept")
+			}
+			if state.RequiredApprovingReviewCount.ValueInt64() != test.wantCount {
+				t.Errorf("Expected count %d in state, got %s", test.wantCount, state.RequiredApprovingReviewCount)
+			}
+			if !state.RequireCodeOwnerReview.IsNull() {
+				t.Errorf("Expected unmanaged parameter to stay null, got %s", state.RequireCodeOwnerReview)
+			}
+			if (len(*puts) > 0) != test.wantPut {
+				t.Errorf("Expected PUT %v, got %d PUTs", test.wantPut, len(*puts))
+			}
+		})
+	}
+}
+
+func TestPullRequestOverlayReadAfterImport(t *testing.T) {
+	client, _ := newRulesetServer(t, `{"id": 123, "rules": [{"type": "pull_request", "parameters": {"required_approving_review_count": 3, "require_code_owner_review": true}}]}`)
+	r := &rulesetPullRequestOverlayResource{client: client}
+
+	state := rulesetPullRequestOverlayResourceModel{
+		rulesetTargetModel: rulesetTargetModel{Repository: types.StringValue("repo"), RulesetID: types.StringValue("123")},
+		RequiredReviewers:  types.ListNull(types.ObjectType{AttrTypes: requiredReviewerAttrTypes}),
+	}
+	private := fakePrivateState{}
+	if _, diags := r.refresh(context.Background(), &state, private); diags.HasError() {
+		t.Fatalf("refresh failed: %v", diags)
+	}
+
+	if state.RequiredApprovingReviewCount.ValueInt64() != 3 || !state.RequireCodeOwnerReview.ValueBool() {
+		t.Errorf("Expected parameters to be adopted, got %+v", state)
+	}
+	if state.RequiredReviewers.IsNull() || len(state.RequiredReviewers.Elements()) != 0 {
+		t.Errorf("Expected empty required_reviewers, got %s", state.RequiredReviewers)
+	}
+	if state.ID.ValueString() != "owner/repo:123" {
+		t.Errorf("Unexpected ID %s", state.ID)
+	}
+	if private[privateKeyPreviousRule] == nil {
+		t.Error("Expected the imported rule to be recorded")
+	}
+}
+
+func TestPullRequestOverlayReadRemovesGoneRuleset(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/owner/repo/rulesets/123", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message": "Not Found"}`, http.StatusNotFound)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client := github.NewClient(nil)
+	client.BaseURL, _ = client.BaseURL.Parse(server.URL + "/")
+	r := &rulesetPullRequestOverlayResource{client: &githubclient.Client{Client: client, Owner: "owner"}}
+
+	state := rulesetPullRequestOverlayResourceModel{
+		rulesetTargetModel: rulesetTargetModel{
+			Repository: types.StringValue("repo"),
+			RulesetID:  types.StringValue("123"),
+			ID:         types.StringValue("owner/repo:123"),
+		},
+		RequiredReviewers: types.ListNull(types.ObjectType{AttrTypes: requiredReviewerAttrTypes}),
+	}
+	found, diags := r.refresh(context.Background(), &state, fakePrivateState{})
+	if diags.HasError() {
+		t.Fatalf("refresh failed: %v", diags)
+	}
+	if found {
+		t.Error("Expected the resource to be removed from state")
+	}
+	if diags.WarningsCount() != 1 {
+		t.Errorf("Expected a warning, got %v", diags)
+	}
+}
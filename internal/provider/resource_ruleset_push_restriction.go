@@ -0,0 +1,569 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/go-github/v74/github"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/knowledge-work/terraform-provider-kw-github/internal/githubclient"
+)
+
+// pushRestrictionParameters maps the push rule types the resource manages to
+// the parameter holding their value. The parameters of file_path_restriction
+// and file_extension_restriction are lists shared with other resources; the
+// other rules hold a single limit and are owned by one resource.
+var pushRestrictionParameters = map[string]string{
+	string(github.RulesetRuleTypeFilePathRestriction):      "restricted_file_paths",
+	string(github.RulesetRuleTypeFileExtensionRestriction): "restricted_file_extensions",
+	string(github.RulesetRuleTypeMaxFileSize):              "max_file_size",
+	string(github.RulesetRuleTypeMaxFilePathLength):        "max_file_path_length",
+}
+
+func NewRulesetPushRestrictionResource() resource.Resource {
+	return &rulesetPushRestrictionResource{}
+}
+
+type rulesetPushRestrictionResource struct {
+	client           *githubclient.Client
+	defaultDriftMode string
+}
+
+type rulesetPushRestrictionResourceModel struct {
+	rulesetTargetModel
+	FilePath          types.String `tfsdk:"file_path"`
+	FileExtension     types.String `tfsdk:"file_extension"`
+	MaxFileSize       types.Int64  `tfsdk:"max_file_size"`
+	MaxFilePathLength types.Int64  `tfsdk:"max_file_path_length"`
+	DriftMode         types.String `tfsdk:"drift_mode"`
+}
+
+// pushRestriction is a single restriction of a push ruleset. Value is the
+// restricted path or extension, or the limit.
+type pushRestriction struct {
+	RuleType string
+	Value    any
+}
+
+func (p pushRestriction) String() string {
+	if isPushLimitRule(p.RuleType) {
+		return "a " + p.RuleType + " rule"
+	}
+	return fmt.Sprintf("%q in its %s rule", p.Value, p.RuleType)
+}
+
+func (r *rulesetPushRestrictionResource) Metadata(
+	_ context.Context,
+	req resource.MetadataRequest,
+	resp *resource.MetadataResponse,
+) {
+	resp.TypeName = req.ProviderTypeName + "_ruleset_push_restriction"
+}
+
+func (r *rulesetPushRestrictionResource) Schema(
+	_ context.Context,
+	_ resource.SchemaRequest,
+	resp *resource.SchemaResponse,
+) {
+	resp.Schema = schema.Schema{
+		Description: "Manages a single restriction of a push ruleset: a restricted file path or extension, or the maximum file size or file path length. Other rules and restrictions are left untouched. Creating the resource fails if the ruleset already has the entry or limit; import it instead.",
+		Attributes: rulesetTargetAttributes(map[string]schema.Attribute{
+			"file_path": schema.StringAttribute{
+				Optional:    true,
+				Description: "A file path that may not be pushed, added to the file_path_restriction rule. Exactly one of file_path, file_extension, max_file_size and max_file_path_length must be set.",
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+					stringvalidator.ExactlyOneOf(
+						path.MatchRoot("file_extension"),
+						path.MatchRoot("max_file_size"),
+						path.MatchRoot("max_file_path_length"),
+					),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"file_extension": schema.StringAttribute{
+				Optional:    true,
+				Description: "A file extension that may not be pushed, e.g. '*.jar', added to the file_extension_restriction rule.",
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"max_file_size": schema.Int64Attribute{
+				Optional:    true,
+				Description: "The maximum size of a pushed file in MB, between 1 and 100. Sets the max_file_size rule.",
+				Validators: []validator.Int64{
+					int64validator.Between(1, 100),
+				},
+				PlanModifiers: []planmodifier.Int64{
+					pushLimitRequiresReplace(),
+				},
+			},
+			"max_file_path_length": schema.Int64Attribute{
+				Optional:    true,
+				Description: "The maximum length of a pushed file path, between 1 and 256. Sets the max_file_path_length rule.",
+				Validators: []validator.Int64{
+					int64validator.Between(1, 256),
+				},
+				PlanModifiers: []planmodifier.Int64{
+					pushLimitRequiresReplace(),
+				},
+			},
+			"drift_mode": driftModeAttribute("the restriction was removed or changed"),
+		}),
+	}
+}
+
+func (r *rulesetPushRestrictionResource) Configure(
+	_ context.Context,
+	req resource.ConfigureRequest,
+	resp *resource.ConfigureResponse,
+) {
+	if req.ProviderData == nil {
+		return
+	}
+	data := req.ProviderData.(*providerData)
+	r.client = data.client
+	r.defaultDriftMode = data.driftMode
+}
+
+func (r *rulesetPushRestrictionResource) Create(
+	ctx context.Context,
+	req resource.CreateRequest,
+	resp *resource.CreateResponse,
+) {
+	var plan rulesetPushRestrictionResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	previous, diags := r.create(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Record whether the rule existed, so that Delete only removes a rule
+	// that this resource added.
+	resp.Diagnostics.Append(setPreviousRule(ctx, resp.Private, previous, nil)...)
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+}
+
+func (r *rulesetPushRestrictionResource) Read(
+	ctx context.Context,
+	req resource.ReadRequest,
+	resp *resource.ReadResponse,
+) {
+	var state rulesetPushRestrictionResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	found, diags := r.refresh(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if !found {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// refresh updates state from GitHub and handles drift of the restriction
+// according to the drift mode. It returns false if the ruleset is gone, or if
+// the restriction was removed and the drift mode lets the plan add it again.
+func (r *rulesetPushRestrictionResource) refresh(
+	ctx context.Context,
+	state *rulesetPushRestrictionResourceModel,
+) (bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	key, err := state.key(r.client.Owner)
+	if err != nil {
+		diags.AddError("Invalid ruleset", err.Error())
+		return false, diags
+	}
+
+	ruleset, diags := getRulesetTarget(ctx, r.client, key)
+	if ruleset == nil {
+		return false, diags
+	}
+
+	imported := state.ID.IsNull()
+	desired := state.restriction()
+	present, limit, err := findPushRestriction(ruleset, desired)
+	if err != nil {
+		diags.AddError("Error reading a ruleset", err.Error())
+		return false, diags
+	}
+
+	switch {
+	case imported:
+		if !present {
+			diags.AddError(
+				"Push restriction not found",
+				fmt.Sprintf("The %s rule of ruleset %s has no matching restriction.", desired.RuleType, key),
+			)
+			return false, diags
+		}
+		state.setLimit(desired.RuleType, limit)
+	case !present || (limit != nil && *limit != desired.Value):
+		useActual, driftDiags := handleRuleDrift(
+			ctx, r.client, key, resolveDriftMode(state.DriftMode, r.defaultDriftMode),
+			func(ruleset *githubclient.Ruleset) error {
+				return setPushRestriction(ruleset, desired)
+			},
+		)
+		diags.Append(driftDiags...)
+		if useActual {
+			if !present {
+				// The restriction was removed; let the plan add it again.
+				return false, diags
+			}
+			state.setLimit(desired.RuleType, limit)
+		}
+	}
+
+	state.ID = types.StringValue(state.resourceID(key))
+	return true, diags
+}
+
+// Update changes a limit in place; every other attribute requires
+// replacement.
+func (r *rulesetPushRestrictionResource) Update(
+	ctx context.Context,
+	req resource.UpdateRequest,
+	resp *resource.UpdateResponse,
+) {
+	var plan rulesetPushRestrictionResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	key, err := plan.key(r.client.Owner)
+	if err != nil {
+		resp.Diagnostics.AddError("Invalid ruleset", err.Error())
+		return
+	}
+
+	restriction := plan.restriction()
+	_, err = r.client.ModifyRuleset(ctx, key, func(ruleset *githubclient.Ruleset) error {
+		return setPushRestriction(ruleset, restriction)
+	})
+	if err != nil {
+		resp.Diagnostics.AddError(rulesetWriteErrorDiagnostic("Error updating ruleset", err))
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+}
+
+// Delete removes the restriction from the ruleset. If this resource added the
+// rule, a list rule is removed with its last entry and a limit rule with its
+// limit; otherwise the rule is left in place.
+func (r *rulesetPushRestrictionResource) Delete(
+	ctx context.Context,
+	req resource.DeleteRequest,
+	resp *resource.DeleteResponse,
+) {
+	var state rulesetPushRestrictionResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.destroy(ctx, &state, req.Private)...)
+}
+
+// destroy removes the restriction, and the rule with it if this resource added
+// the rule according to private.
+func (r *rulesetPushRestrictionResource) destroy(
+	ctx context.Context,
+	state *rulesetPushRestrictionResourceModel,
+	private privateState,
+) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	key, err := state.key(r.client.Owner)
+	if err != nil {
+		diags.AddError("Invalid ruleset", err.Error())
+		return diags
+	}
+
+	previous, diags := getPreviousRule(ctx, private)
+	if diags.HasError() {
+		return diags
+	}
+	addedRule := previous != nil && !previous.Exists
+
+	_, err = r.client.ModifyRuleset(ctx, key, func(ruleset *githubclient.Ruleset) error {
+		return removePushRestriction(ruleset, state.restriction(), addedRule)
+	})
+	if isRulesetGone(err) {
+		// Nothing left to remove the restriction from.
+		return diags
+	}
+	if err != nil {
+		diags.AddError(rulesetWriteErrorDiagnostic("Error removing a push restriction", err))
+	}
+	return diags
+}
+
+// ImportState accepts [owner/]repo:ruleset_id:rule_type:value for
+// file_path_restriction and file_extension_restriction, and
+// [owner/]repo:ruleset_id:rule_type for the limits, whose value is read from
+// the ruleset.
+func (r *rulesetPushRestrictionResource) ImportState(
+	ctx context.Context,
+	req resource.ImportStateRequest,
+	resp *resource.ImportStateResponse,
+) {
+	extra := []string{"rule_type", "value"}
+	if parts := strings.SplitN(req.ID, ":", 4); len(parts) > 2 && isPushLimitRule(parts[2]) {
+		extra = extra[:1]
+	}
+
+	fields, ok := importRulesetTarget(ctx, req.ID, resp, extra...)
+	if !ok {
+		return
+	}
+
+	switch ruleType := fields[0]; ruleType {
+	case string(github.RulesetRuleTypeFilePathRestriction):
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("file_path"), fields[1])...)
+	case string(github.RulesetRuleTypeFileExtensionRestriction):
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("file_extension"), fields[1])...)
+	case string(github.RulesetRuleTypeMaxFileSize), string(github.RulesetRuleTypeMaxFilePathLength):
+		// Any value selects the rule; Read stores the actual limit.
+		resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root(ruleType), int64(0))...)
+	default:
+		resp.Diagnostics.AddError(
+			"Unexpected Import Identifier",
+			fmt.Sprintf("Unknown push rule type %q. Expected one of: %s.", ruleType, strings.Join(pushRestrictionRuleTypes(), ", ")),
+		)
+	}
+}
+
+// create adds the restriction in plan and returns its rule as it was before.
+func (r *rulesetPushRestrictionResource) create(
+	ctx context.Context,
+	plan *rulesetPushRestrictionResourceModel,
+) (*githubclient.RulesetRule, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	key, err := plan.key(r.client.Owner)
+	if err != nil {
+		diags.AddError("Invalid ruleset", err.Error())
+		return nil, diags
+	}
+
+	restriction := plan.restriction()
+	var previous *githubclient.RulesetRule
+	_, err = r.client.ModifyRuleset(ctx, key, func(ruleset *githubclient.Ruleset) error {
+		previous = ruleset.Rule(restriction.RuleType)
+		return addPushRestriction(ruleset, restriction)
+	})
+	if errors.Is(err, errRulesetEntryExists) {
+		diags.AddError(entryExistsDiagnostic(key, restriction.String(), plan.resourceID(key)))
+		return nil, diags
+	}
+	if err != nil {
+		diags.AddError(rulesetWriteErrorDiagnostic("Error updating ruleset", err))
+		return nil, diags
+	}
+
+	plan.ID = types.StringValue(plan.resourceID(key))
+	return previous, diags
+}
+
+// restriction returns the restriction set in the model.
+func (m *rulesetPushRestrictionResourceModel) restriction() pushRestriction {
+	switch {
+	case !m.FilePath.IsNull():
+		return pushRestriction{string(github.RulesetRuleTypeFilePathRestriction), m.FilePath.ValueString()}
+	case !m.FileExtension.IsNull():
+		return pushRestriction{string(github.RulesetRuleTypeFileExtensionRestriction), m.FileExtension.ValueString()}
+	case !m.MaxFileSize.IsNull():
+		return pushRestriction{string(github.RulesetRuleTypeMaxFileSize), m.MaxFileSize.ValueInt64()}
+	default:
+		return pushRestriction{string(github.RulesetRuleTypeMaxFilePathLength), m.MaxFilePathLength.ValueInt64()}
+	}
+}
+
+// setLimit stores limit in the attribute of ruleType. It does nothing for
+// list rules, whose limit is nil.
+func (m *rulesetPushRestrictionResourceModel) setLimit(ruleType string, limit *int64) {
+	if limit == nil {
+		return
+	}
+	switch ruleType {
+	case string(github.RulesetRuleTypeMaxFileSize):
+		m.MaxFileSize = types.Int64Value(*limit)
+	case string(github.RulesetRuleTypeMaxFilePathLength):
+		m.MaxFilePathLength = types.Int64Value(*limit)
+	}
+}
+
+// resourceID returns owner/repo:ruleset_id:rule_type[:value], leaving out the
+// value of limits, which may change in place.
+func (m *rulesetPushRestrictionResourceModel) resourceID(key githubclient.RulesetKey) string {
+	restriction := m.restriction()
+	if value, ok := restriction.Value.(string); ok {
+		return rulesetTargetID(key, restriction.RuleType, value)
+	}
+	return rulesetTargetID(key, restriction.RuleType)
+}
+
+// pushLimitRequiresReplace replaces the resource when it switches from or to
+// another restriction. A changed limit is updated in place.
+func pushLimitRequiresReplace() planmodifier.Int64 {
+	return int64planmodifier.RequiresReplaceIf(
+		func(_ context.Context, req planmodifier.Int64Request, resp *int64planmodifier.RequiresReplaceIfFuncResponse) {
+			resp.RequiresReplace = req.StateValue.IsNull() || req.PlanValue.IsNull()
+		},
+		"Switching to another restriction requires replacement.",
+		"Switching to another restriction requires replacement.",
+	)
+}
+
+func isPushLimitRule(ruleType string) bool {
+	return ruleType == string(github.RulesetRuleTypeMaxFileSize) ||
+		ruleType == string(github.RulesetRuleTypeMaxFilePathLength)
+}
+
+func pushRestrictionRuleTypes() []string {
+	ruleTypes := make([]string, 0, len(pushRestrictionParameters))
+	for ruleType := range pushRestrictionParameters {
+		ruleTypes = append(ruleTypes, ruleType)
+	}
+	slices.Sort(ruleTypes)
+	return ruleTypes
+}
+
+// findPushRestriction reports whether the ruleset has restriction. For limit
+// rules, it also returns the limit the ruleset has, which may differ from the
+// restriction's.
+func findPushRestriction(ruleset *githubclient.Ruleset, restriction pushRestriction) (bool, *int64, error) {
+	rule := ruleset.Rule(restriction.RuleType)
+	if rule == nil {
+		return false, nil, nil
+	}
+	param := pushRestrictionParameters[restriction.RuleType]
+
+	if isPushLimitRule(restriction.RuleType) {
+		var limit int64
+		ok, err := rule.Parameter(param, &limit)
+		if err != nil {
+			return false, nil, fmt.Errorf("failed to decode %s: %w", param, err)
+		}
+		return ok, &limit, nil
+	}
+
+	var entries []string
+	if _, err := rule.Parameter(param, &entries); err != nil {
+		return false, nil, fmt.Errorf("failed to decode %s: %w", param, err)
+	}
+	return slices.Contains(entries, restriction.Value.(string)), nil, nil
+}
+
+// addPushRestriction adds restriction to the ruleset like setPushRestriction.
+// It returns errRulesetEntryExists if the ruleset already has the entry of a
+// list rule, or any limit of a limit rule.
+func addPushRestriction(ruleset *githubclient.Ruleset, restriction pushRestriction) error {
+	present, _, err := findPushRestriction(ruleset, restriction)
+	if err != nil {
+		return err
+	}
+	if present {
+		return errRulesetEntryExists
+	}
+	return setPushRestriction(ruleset, restriction)
+}
+
+// setPushRestriction adds restriction to the ruleset, creating its rule if
+// the ruleset has none, or sets the limit of a limit rule. Other entries of a
+// list rule are kept.
+func setPushRestriction(ruleset *githubclient.Ruleset, restriction pushRestriction) error {
+	param := pushRestrictionParameters[restriction.RuleType]
+	value := restriction.Value
+
+	rule := ruleset.Rule(restriction.RuleType)
+	if !isPushLimitRule(restriction.RuleType) {
+		var entries []string
+		if rule != nil {
+			if _, err := rule.Parameter(param, &entries); err != nil {
+				return fmt.Errorf("failed to decode %s: %w", param, err)
+			}
+		}
+		if slices.Contains(entries, value.(string)) {
+			return nil
+		}
+		value = append(entries, value.(string))
+	}
+
+	if rule == nil {
+		rule, err := githubclient.NewRulesetRule(restriction.RuleType, map[string]any{param: value})
+		if err != nil {
+			return err
+		}
+		ruleset.SetRule(rule)
+		return nil
+	}
+	return rule.SetParameter(param, value)
+}
+
+// removePushRestriction removes restriction from the ruleset. When
+// removeEmptyRule is set, list rules are removed with their last entry and
+// limit rules with their limit. Otherwise an empty list rule is kept, and so
+// is a limit rule, which cannot exist without its limit.
+func removePushRestriction(ruleset *githubclient.Ruleset, restriction pushRestriction, removeEmptyRule bool) error {
+	if isPushLimitRule(restriction.RuleType) {
+		if removeEmptyRule {
+			ruleset.RemoveRule(restriction.RuleType)
+		}
+		return nil
+	}
+
+	rule := ruleset.Rule(restriction.RuleType)
+	if rule == nil {
+		return nil
+	}
+	param := pushRestrictionParameters[restriction.RuleType]
+
+	var entries []string
+	if _, err := rule.Parameter(param, &entries); err != nil {
+		return fmt.Errorf("failed to decode %s: %w", param, err)
+	}
+	kept := slices.DeleteFunc(entries, func(entry string) bool {
+		return entry == restriction.Value.(string)
+	})
+
+	if len(kept) == 0 && removeEmptyRule {
+		ruleset.RemoveRule(restriction.RuleType)
+		return nil
+	}
+	return rule.SetParameter(param, kept)
+}
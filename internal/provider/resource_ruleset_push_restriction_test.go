@@ -0,0 +1,354 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+const pushRuleset = `{"id": 123, "target": "push", "rules": [{"type": "file_path_restriction", "parameters": {"restricted_file_paths": ["secrets/**"]}}, {"type": "max_file_size", "parameters": {"max_file_size": 10}}]}`
+
+func TestAddPushRestriction(t *testing.T) {
+	tests := []struct {
+		name        string
+		restriction pushRestriction
+		want        string
+		wantErr     error
+	}{
+		{
+			name:        "appends a path",
+			restriction: pushRestriction{"file_path_restriction", ".env"},
+			want:        `[{"parameters":{"restricted_file_paths":["secrets/**",".env"]},"type":"file_path_restriction"},{"type":"max_file_size","parameters":{"max_file_size":10}}]`,
+		},
+		{
+			name:        "creates an extension rule",
+			restriction: pushRestriction{"file_extension_restriction", "*.jar"},
+			want:        `[{"type":"file_path_restriction","parameters":{"restricted_file_paths":["secrets/**"]}},{"type":"max_file_size","parameters":{"max_file_size":10}},{"parameters":{"restricted_file_extensions":["*.jar"]},"type":"file_extension_restriction"}]`,
+		},
+		{
+			name:        "creates a limit rule",
+			restriction: pushRestriction{"max_file_path_length", int64(200)},
+			want:        `[{"type":"file_path_restriction","parameters":{"restricted_file_paths":["secrets/**"]}},{"type":"max_file_size","parameters":{"max_file_size":10}},{"parameters":{"max_file_path_length":200},"type":"max_file_path_length"}]`,
+		},
+		{
+			name:        "rejects an existing path",
+			restriction: pushRestriction{"file_path_restriction", "secrets/**"},
+			wantErr:     errRulesetEntryExists,
+		},
+		{
+			name:        "rejects an existing limit",
+			restriction: pushRestriction{"max_file_size", int64(50)},
+			wantErr:     errRulesetEntryExists,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ruleset := decodeRuleset(t, pushRuleset)
+			err := addPushRestriction(ruleset, test.restriction)
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("Expected error %v, got %v", test.wantErr, err)
+			}
+			if test.wantErr != nil {
+				return
+			}
+			data, _ := json.Marshal(ruleset)
+			if got := rulesJSON(t, data); got != test.want {
+				t.Errorf("Expected rules %s, got %s", test.want, got)
+			}
+		})
+	}
+}
+
+func TestSetPushRestriction(t *testing.T) {
+	tests := []struct {
+		name        string
+		restriction pushRestriction
+		want        string
+	}{
+		{
+			name:        "keeps an existing path",
+			restriction: pushRestriction{"file_path_restriction", "secrets/**"},
+			want:        `[{"type":"file_path_restriction","parameters":{"restricted_file_paths":["secrets/**"]}},{"type":"max_file_size","parameters":{"max_file_size":10}}]`,
+		},
+		{
+			name:        "changes a limit",
+			restriction: pushRestriction{"max_file_size", int64(50)},
+			want:        `[{"type":"file_path_restriction","parameters":{"restricted_file_paths":["secrets/**"]}},{"parameters":{"max_file_size":50},"type":"max_file_size"}]`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ruleset := decodeRuleset(t, pushRuleset)
+			if err := setPushRestriction(ruleset, test.restriction); err != nil {
+				t.Fatalf("setPushRestriction failed: %v", err)
+			}
+			data, _ := json.Marshal(ruleset)
+			if got := rulesJSON(t, data); got != test.want {
+				t.Errorf("Expected rules %s, got %s", test.want, got)
+			}
+		})
+	}
+}
+
+func TestRemovePushRestriction(t *testing.T) {
+	const ruleset = `{"rules": [{"type": "file_path_restriction", "parameters": {"restricted_file_paths": ["secrets/**", ".env"]}}, {"type": "max_file_size", "parameters": {"max_file_size": 10}}]}`
+
+	tests := []struct {
+		name            string
+		restrictions    []pushRestriction
+		removeEmptyRule bool
+		want            string
+	}{
+		{
+			name:         "keeps the other paths",
+			restrictions: []pushRestriction{{"file_path_restriction", ".env"}},
+			want:         `[{"parameters":{"restricted_file_paths":["secrets/**"]},"type":"file_path_restriction"},{"type":"max_file_size","parameters":{"max_file_size":10}}]`,
+		},
+		{
+			name:            "removes an empty rule it added",
+			restrictions:    []pushRestriction{{"file_path_restriction", ".env"}, {"file_path_restriction", "secrets/**"}},
+			removeEmptyRule: true,
+			want:            `[{"type":"max_file_size","parameters":{"max_file_size":10}}]`,
+		},
+		{
+			name:         "keeps an empty rule it did not add",
+			restrictions: []pushRestriction{{"file_path_restriction", ".env"}, {"file_path_restriction", "secrets/**"}},
+			want:         `[{"parameters":{"restricted_file_paths":[]},"type":"file_path_restriction"},{"type":"max_file_size","parameters":{"max_file_size":10}}]`,
+		},
+		{
+			name:            "removes a limit rule it added",
+			restrictions:    []pushRestriction{{"max_file_size", int64(10)}},
+			removeEmptyRule: true,
+			want:            `[{"type":"file_path_restriction","parameters":{"restricted_file_paths":["secrets/**",".env"]}}]`,
+		},
+		{
+			name:         "keeps a limit rule it did not add",
+			restrictions: []pushRestriction{{"max_file_size", int64(10)}},
+			want:         `[{"type":"file_path_restriction","parameters":{"restricted_file_paths":["secrets/**",".env"]}},{"type":"max_file_size","parameters":{"max_file_size":10}}]`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ruleset := decodeRuleset(t, ruleset)
+			for _, restriction := range test.restrictions {
+				if err := removePushRestriction(ruleset, restriction, test.removeEmptyRule); err != nil {
+					t.Fatalf("removePushRestriction failed: %v", err)
+				}
+			}
+			data, _ := json.Marshal(ruleset)
+			if got := rulesJSON(t, data); got != test.want {
+				t.Errorf("Expected rules %s, got %s", test.want, got)
+			}
+		})
+	}
+}
+
+func TestPushRestrictionCreateAndDelete(t *testing.T) {
+	tests := []struct {
+		name      string
+		state     rulesetPushRestrictionResourceModel
+		wantError bool
+		want      string
+	}{
+		{
+			name:  "keeps a rule it did not add",
+			state: rulesetPushRestrictionResourceModel{FilePath: types.StringValue(".env")},
+			want:  `[{"parameters":{"restricted_file_paths":["secrets/**"]},"type":"file_path_restriction"},{"type":"max_file_size","parameters":{"max_file_size":10}}]`,
+		},
+		{
+			name:  "removes a rule it added",
+			state: rulesetPushRestrictionResourceModel{FileExtension: types.StringValue("*.jar")},
+			want:  `[{"type":"file_path_restriction","parameters":{"restricted_file_paths":["secrets/**"]}},{"type":"max_file_size","parameters":{"max_file_size":10}}]`,
+		},
+		{
+			name:      "rejects an existing path",
+			state:     rulesetPushRestrictionResourceModel{FilePath: types.StringValue("secrets/**")},
+			wantError: true,
+		},
+		{
+			name:      "rejects an existing limit",
+			state:     rulesetPushRestrictionResourceModel{MaxFileSize: types.Int64Value(50)},
+			wantError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client, puts := newRulesetServer(t, pushRuleset)
+			r := &rulesetPushRestrictionResource{client: client}
+
+			ctx := context.Background()
+			plan := test.state
+			plan.rulesetTargetModel = rulesetTargetModel{Repository: types.StringValue("repo"), RulesetID: types.StringValue("123")}
+			previous, diags := r.create(ctx, &plan)
+			if diags.HasError() != test.wantError {
+				t.Fatalf("Expected error %v, got %v", test.wantError, diags)
+			}
+			if test.wantError {
+				if got := diags.Errors()[0].Summary(); got != "Ruleset entry already exists" {
+					t.Errorf("Unexpected error %q", got)
+				}
+				if len(*puts) != 0 {
+					t.Errorf("Expected no PUT, got %d", len(*puts))
+				}
+				return
+			}
+
+			private := fakePrivateState{}
+			if diags := setPreviousRule(ctx, private, previous, nil); diags.HasError() {
+				t.Fatal(diags)
+			}
+			if diags := r.destroy(ctx, &plan, private); diags.HasError() {
+				t.Fatalf("destroy failed: %v", diags)
+			}
+			if len(*puts) != 2 {
+				t.Fatalf("Expected 2 PUTs, got %d", len(*puts))
+			}
+			if got := rulesJSON(t, (*puts)[1]); got != test.want {
+				t.Errorf("Expected rules %s, got %s", test.want, got)
+			}
+		})
+	}
+}
+
+func TestPushRestrictionReadDrift(t *testing.T) {
+	tests := []struct {
+		name      string
+		driftMode string
+		state     rulesetPushRestrictionResourceModel
+		wantKept  bool
+		wantSize  int64
+		wantPut   bool
+	}{
+		{
+			name:      "restore changed limit",
+			driftMode: driftModeRestore,
+			state:     rulesetPushRestrictionResourceModel{MaxFileSize: types.Int64Value(50)},
+			wantKept:  true,
+			wantSize:  50,
+			wantPut:   true,
+		},
+		{
+			name:      "report changed limit",
+			driftMode: driftModeReport,
+			state:     rulesetPushRestrictionResourceModel{MaxFileSize: types.Int64Value(50)},
+			wantKept:  true,
+			wantSize:  10,
+		},
+		{
+			name:      "ignore changed limit",
+			driftMode: driftModeIgnore,
+			state:     rulesetPushRestrictionResourceModel{MaxFileSize: types.Int64Value(50)},
+			wantKept:  true,
+			wantSize:  50,
+		},
+		{
+			name:      "report removed path",
+			driftMode: driftModeReport,
+			state:     rulesetPushRestrictionResourceModel{FilePath: types.StringValue(".env")},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client, puts := newRulesetServer(t, pushRuleset)
+			r := &rulesetPushRestrictionResource{client: client, defaultDriftMode: test.driftMode}
+
+			state := test.state
+			state.rulesetTargetModel = rulesetTargetModel{
+				Repository: types.StringValue("repo"),
+				RulesetID:  types.StringValue("123"),
+				ID:         types.StringValue("owner/repo:123"),
+			}
+
+			found, diags := r.refresh(context.Background(), &state)
+			if diags.HasError() {
+				t.Fatalf("refresh failed: %v", diags)
+			}
+			if found != test.wantKept {
+				t.Fatalf("Expected the resource to be kept %v, got %v", test.wantKept, found)
+			}
+			if found && state.MaxFileSize.ValueInt64() != test.wantSize {
+				t.Errorf("Expected max_file_size %d, got %s", test.wantSize, state.MaxFileSize)
+			}
+			if (len(*puts) > 0) != test.wantPut {
+				t.Errorf("Expected PUT %v, got %d PUTs", test.wantPut, len(*puts))
+			}
+		})
+	}
+}
+
+func TestPushRestrictionImport(t *testing.T) {
+	client, _ := newRulesetServer(t, pushRuleset)
+	r := &rulesetPushRestrictionResource{client: client}
+
+	tests := []struct {
+		id         string
+		wantErrors bool
+		check      func(t *testing.T, got rulesetPushRestrictionResourceModel)
+	}{
+		{
+			id: "repo:123:file_path_restriction:secrets/**",
+			check: func(t *testing.T, got rulesetPushRestrictionResourceModel) {
+				if got.FilePath.ValueString() != "secrets/**" {
+					t.Errorf("Unexpected file_path %s", got.FilePath)
+				}
+			},
+		},
+		{
+			id: "repo:123:max_file_size",
+			check: func(t *testing.T, got rulesetPushRestrictionResourceModel) {
+				if got.MaxFileSize.ValueInt64() != 10 {
+					t.Errorf("Expected the limit to be read from the ruleset, got %s", got.MaxFileSize)
+				}
+			},
+		},
+		{id: "repo:123:max_file_size:10", wantErrors: true},
+		{id: "repo:123:file_path_restriction", wantErrors: true},
+		{id: "repo:123:workflows:x", wantErrors: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.id, func(t *testing.T) {
+			ctx := context.Background()
+
+			var schemaResp resource.SchemaResponse
+			r.Schema(ctx, resource.SchemaRequest{}, &schemaResp)
+			resp := &resource.ImportStateResponse{State: tfsdk.State{
+				Schema: schemaResp.Schema,
+				Raw:    tftypes.NewValue(schemaResp.Schema.Type().TerraformType(ctx), nil),
+			}}
+			r.ImportState(ctx, resource.ImportStateRequest{ID: test.id}, resp)
+
+			if resp.Diagnostics.HasError() != test.wantErrors {
+				t.Fatalf("Expected errors %v, got %v", test.wantErrors, resp.Diagnostics)
+			}
+			if test.wantErrors {
+				return
+			}
+
+			var got rulesetPushRestrictionResourceModel
+			resp.Diagnostics.Append(resp.State.Get(ctx, &got)...)
+			if resp.Diagnostics.HasError() {
+				t.Fatal(resp.Diagnostics)
+			}
+			found, diags := r.refresh(ctx, &got)
+			if diags.HasError() {
+				t.Fatalf("refresh failed: %v", diags)
+			}
+			if !found {
+				t.Fatal("Expected the resource to be kept")
+			}
+			test.check(t, got)
+		})
+	}
+}
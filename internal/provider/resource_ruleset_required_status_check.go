@@ -0,0 +1,422 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/go-github/v74/github"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/int64planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/knowledge-work/terraform-provider-kw-github/internal/githubclient"
+)
+
+func NewRulesetRequiredStatusCheckResource() resource.Resource {
+	return &rulesetRequiredStatusCheckResource{}
+}
+
+type rulesetRequiredStatusCheckResource struct {
+	client           *githubclient.Client
+	defaultDriftMode string
+}
+
+type rulesetRequiredStatusCheckResourceModel struct {
+	rulesetTargetModel
+	Context       types.String `tfsdk:"context"`
+	IntegrationID types.Int64  `tfsdk:"integration_id"`
+	DriftMode     types.String `tfsdk:"drift_mode"`
+}
+
+func (r *rulesetRequiredStatusCheckResource) Metadata(
+	_ context.Context,
+	req resource.MetadataRequest,
+	resp *resource.MetadataResponse,
+) {
+	resp.TypeName = req.ProviderTypeName + "_ruleset_required_status_check"
+}
+
+func (r *rulesetRequiredStatusCheckResource) Schema(
+	_ context.Context,
+	_ resource.SchemaRequest,
+	resp *resource.SchemaResponse,
+) {
+	resp.Schema = schema.Schema{
+		Description: "Manages a single required status check of a repository ruleset. Other status checks are left untouched. Creating the resource fails if a check with the same context is already required, whatever app reports it; import it instead.",
+		Attributes: rulesetTargetAttributes(map[string]schema.Attribute{
+			"context": schema.StringAttribute{
+				Required:    true,
+				Description: "The name of the status check that must pass, e.g. 'ci/build'.",
+				Validators: []validator.String{
+					stringvalidator.LengthAtLeast(1),
+				},
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"integration_id": schema.Int64Attribute{
+				Optional:    true,
+				Description: "The ID of the GitHub App that must report the status check. Any app may report it if not set.",
+				PlanModifiers: []planmodifier.Int64{
+					int64planmodifier.RequiresReplace(),
+				},
+			},
+			"drift_mode": driftModeAttribute("the status check was removed or its app changed"),
+		}),
+	}
+}
+
+func (r *rulesetRequiredStatusCheckResource) Configure(
+	_ context.Context,
+	req resource.ConfigureRequest,
+	resp *resource.ConfigureResponse,
+) {
+	if req.ProviderData == nil {
+		return
+	}
+	data := req.ProviderData.(*providerData)
+	r.client = data.client
+	r.defaultDriftMode = data.driftMode
+}
+
+func (r *rulesetRequiredStatusCheckResource) Create(
+	ctx context.Context,
+	req resource.CreateRequest,
+	resp *resource.CreateResponse,
+) {
+	var plan rulesetRequiredStatusCheckResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	previous, diags := r.create(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Record whether the rule existed, so that Delete only removes a rule
+	// that this resource added.
+	resp.Diagnostics.Append(setPreviousRule(ctx, resp.Private, previous, nil)...)
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+}
+
+func (r *rulesetRequiredStatusCheckResource) Read(
+	ctx context.Context,
+	req resource.ReadRequest,
+	resp *resource.ReadResponse,
+) {
+	var state rulesetRequiredStatusCheckResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	found, diags := r.refresh(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if !found {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// refresh updates state from GitHub and handles drift of the status check
+// according to the drift mode. It returns false if the ruleset is gone, or if
+// the check was removed and the drift mode lets the plan add it again.
+func (r *rulesetRequiredStatusCheckResource) refresh(
+	ctx context.Context,
+	state *rulesetRequiredStatusCheckResourceModel,
+) (bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	key, err := state.key(r.client.Owner)
+	if err != nil {
+		diags.AddError("Invalid ruleset", err.Error())
+		return false, diags
+	}
+
+	ruleset, diags := getRulesetTarget(ctx, r.client, key)
+	if ruleset == nil {
+		return false, diags
+	}
+
+	_, checks, err := requiredStatusChecks(ruleset)
+	if err != nil {
+		diags.AddError("Error reading a ruleset", err.Error())
+		return false, diags
+	}
+
+	// Right after import only the context is known; take the integration ID
+	// from the ruleset.
+	if state.ID.IsNull() {
+		var matches []*github.RuleStatusCheck
+		for _, check := range checks {
+			if check.Context == state.Context.ValueString() {
+				matches = append(matches, check)
+			}
+		}
+		if len(matches) != 1 {
+			diags.AddError(
+				"Status check not found",
+				fmt.Sprintf("Ruleset %s has %d required status checks named %q; exactly one is required for import.",
+					key, len(matches), state.Context.ValueString()),
+			)
+			return false, diags
+		}
+		state.IntegrationID = types.Int64PointerValue(matches[0].IntegrationID)
+		state.ID = types.StringValue(rulesetTargetID(key, state.Context.ValueString()))
+		return true, diags
+	}
+
+	check := state.statusCheck()
+	i := statusCheckIndex(checks, check.Context)
+	if i < 0 || !int64PointersEqual(checks[i].IntegrationID, check.IntegrationID) {
+		useActual, driftDiags := handleRuleDrift(
+			ctx, r.client, key, resolveDriftMode(state.DriftMode, r.defaultDriftMode),
+			func(ruleset *githubclient.Ruleset) error {
+				return setRequiredStatusCheck(ruleset, check)
+			},
+		)
+		diags.Append(driftDiags...)
+		if useActual {
+			if i < 0 {
+				// The check was removed; let the plan add it again.
+				return false, diags
+			}
+			state.IntegrationID = types.Int64PointerValue(checks[i].IntegrationID)
+		}
+	}
+	return true, diags
+}
+
+// Update only changes drift_mode; every other attribute requires replacement,
+// so there is nothing to write.
+func (r *rulesetRequiredStatusCheckResource) Update(
+	ctx context.Context,
+	req resource.UpdateRequest,
+	resp *resource.UpdateResponse,
+) {
+	var plan rulesetRequiredStatusCheckResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+}
+
+// Delete removes the status check from the ruleset. The required_status_checks
+// rule is removed with its last status check only if this resource added the
+// rule; otherwise the empty rule is left in place.
+func (r *rulesetRequiredStatusCheckResource) Delete(
+	ctx context.Context,
+	req resource.DeleteRequest,
+	resp *resource.DeleteResponse,
+) {
+	var state rulesetRequiredStatusCheckResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.destroy(ctx, &state, req.Private)...)
+}
+
+// destroy removes the status check, and the rule with it if this resource
+// added the rule according to private.
+func (r *rulesetRequiredStatusCheckResource) destroy(
+	ctx context.Context,
+	state *rulesetRequiredStatusCheckResourceModel,
+	private privateState,
+) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	key, err := state.key(r.client.Owner)
+	if err != nil {
+		diags.AddError("Invalid ruleset", err.Error())
+		return diags
+	}
+
+	previous, diags := getPreviousRule(ctx, private)
+	if diags.HasError() {
+		return diags
+	}
+	addedRule := previous != nil && !previous.Exists
+
+	_, err = r.client.ModifyRuleset(ctx, key, func(ruleset *githubclient.Ruleset) error {
+		return removeRequiredStatusCheck(ruleset, state.statusCheck(), addedRule)
+	})
+	if isRulesetGone(err) {
+		// Nothing left to remove the status check from.
+		return diags
+	}
+	if err != nil {
+		diags.AddError(rulesetWriteErrorDiagnostic("Error removing a required status check", err))
+	}
+	return diags
+}
+
+func (r *rulesetRequiredStatusCheckResource) ImportState(
+	ctx context.Context,
+	req resource.ImportStateRequest,
+	resp *resource.ImportStateResponse,
+) {
+	fields, ok := importRulesetTarget(ctx, req.ID, resp, "context")
+	if !ok {
+		return
+	}
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("context"), fields[0])...)
+}
+
+// create adds the status check in plan and returns the
+// required_status_checks rule as it was before.
+func (r *rulesetRequiredStatusCheckResource) create(
+	ctx context.Context,
+	plan *rulesetRequiredStatusCheckResourceModel,
+) (*githubclient.RulesetRule, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	key, err := plan.key(r.client.Owner)
+	if err != nil {
+		diags.AddError("Invalid ruleset", err.Error())
+		return nil, diags
+	}
+
+	check := plan.statusCheck()
+	var previous *githubclient.RulesetRule
+	_, err = r.client.ModifyRuleset(ctx, key, func(ruleset *githubclient.Ruleset) error {
+		previous = ruleset.Rule(string(github.RulesetRuleTypeRequiredStatusChecks))
+		return addRequiredStatusCheck(ruleset, check)
+	})
+	if errors.Is(err, errRulesetEntryExists) {
+		diags.AddError(entryExistsDiagnostic(
+			key, fmt.Sprintf("required status check %q", check.Context), rulesetTargetID(key, check.Context),
+		))
+		return nil, diags
+	}
+	if err != nil {
+		diags.AddError(rulesetWriteErrorDiagnostic("Error updating ruleset", err))
+		return nil, diags
+	}
+
+	plan.ID = types.StringValue(rulesetTargetID(key, check.Context))
+	return previous, diags
+}
+
+// statusCheck returns the status check described by the model.
+func (m *rulesetRequiredStatusCheckResourceModel) statusCheck() *github.RuleStatusCheck {
+	return &github.RuleStatusCheck{
+		Context:       m.Context.ValueString(),
+		IntegrationID: m.IntegrationID.ValueInt64Pointer(),
+	}
+}
+
+// requiredStatusChecks returns the ruleset's required_status_checks rule, or
+// nil if it has none, and its status checks.
+func requiredStatusChecks(ruleset *githubclient.Ruleset) (*githubclient.RulesetRule, []*github.RuleStatusCheck, error) {
+	rule := ruleset.Rule(string(github.RulesetRuleTypeRequiredStatusChecks))
+	if rule == nil {
+		return nil, nil, nil
+	}
+
+	var checks []*github.RuleStatusCheck
+	if _, err := rule.Parameter("required_status_checks", &checks); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode required status checks: %w", err)
+	}
+	return rule, checks, nil
+}
+
+// statusCheckIndex returns the index of the check with the given context in
+// checks, or -1. A context identifies a check whatever app reports it.
+func statusCheckIndex(checks []*github.RuleStatusCheck, context string) int {
+	for i, existing := range checks {
+		if existing.Context == context {
+			return i
+		}
+	}
+	return -1
+}
+
+// addRequiredStatusCheck adds check to the ruleset's required_status_checks
+// rule, creating the rule if the ruleset has none. It returns
+// errRulesetEntryExists if a check with the same context is already required,
+// whatever app reports it.
+func addRequiredStatusCheck(ruleset *githubclient.Ruleset, check *github.RuleStatusCheck) error {
+	_, checks, err := requiredStatusChecks(ruleset)
+	if err != nil {
+		return err
+	}
+	if statusCheckIndex(checks, check.Context) >= 0 {
+		return errRulesetEntryExists
+	}
+	return setRequiredStatusCheck(ruleset, check)
+}
+
+// setRequiredStatusCheck adds check to the ruleset's required_status_checks
+// rule, or replaces the check with the same context, creating the rule if the
+// ruleset has none.
+func setRequiredStatusCheck(ruleset *githubclient.Ruleset, check *github.RuleStatusCheck) error {
+	rule, checks, err := requiredStatusChecks(ruleset)
+	if err != nil {
+		return err
+	}
+
+	if rule == nil {
+		rule, err = githubclient.NewRulesetRule(
+			string(github.RulesetRuleTypeRequiredStatusChecks), github.RequiredStatusChecksRuleParameters{},
+		)
+		if err != nil {
+			return err
+		}
+		ruleset.SetRule(rule)
+	}
+
+	if i := statusCheckIndex(checks, check.Context); i >= 0 {
+		checks[i] = check
+	} else {
+		checks = append(checks, check)
+	}
+	return rule.SetParameter("required_status_checks", checks)
+}
+
+// removeRequiredStatusCheck removes the check with the context of check from
+// the ruleset's required_status_checks rule, whatever app reports it. If no
+// check is left, the rule itself is removed when removeEmptyRule is set.
+func removeRequiredStatusCheck(ruleset *githubclient.Ruleset, check *github.RuleStatusCheck, removeEmptyRule bool) error {
+	rule, checks, err := requiredStatusChecks(ruleset)
+	if err != nil || rule == nil {
+		return err
+	}
+
+	i := statusCheckIndex(checks, check.Context)
+	if i < 0 {
+		return nil
+	}
+	checks = append(checks[:i], checks[i+1:]...)
+
+	if len(checks) == 0 && removeEmptyRule {
+		ruleset.RemoveRule(rule.Type())
+		return nil
+	}
+	return rule.SetParameter("required_status_checks", checks)
+}
+
+func int64PointersEqual(a, b *int64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,297 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/go-github/v74/github"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestAddRequiredStatusCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		ruleset string
+		check   *github.RuleStatusCheck
+		want    string
+		wantErr error
+	}{
+		{
+			name:    "creates the rule",
+			ruleset: `{"rules": [{"type": "deletion"}]}`,
+			check:   &github.RuleStatusCheck{Context: "ci/build"},
+			want:    `[{"type":"deletion"},{"parameters":{"required_status_checks":[{"context":"ci/build"}],"strict_required_status_checks_policy":false},"type":"required_status_checks"}]`,
+		},
+		{
+			name:    "appends to the rule",
+			ruleset: `{"rules": [{"type": "required_status_checks", "parameters": {"required_status_checks": [{"context": "lint"}], "strict_required_status_checks_policy": true, "do_not_enforce_on_create": true}}]}`,
+			check:   &github.RuleStatusCheck{Context: "ci/build", IntegrationID: github.Ptr(int64(15368))},
+			want:    `[{"parameters":{"do_not_enforce_on_create":true,"required_status_checks":[{"context":"lint"},{"context":"ci/build","integration_id":15368}],"strict_required_status_checks_policy":true},"type":"required_status_checks"}]`,
+		},
+		{
+			name:    "rejects an existing check",
+			ruleset: `{"rules": [{"type": "required_status_checks", "parameters": {"required_status_checks": [{"context": "ci/build"}], "strict_required_status_checks_policy": true}}]}`,
+			check:   &github.RuleStatusCheck{Context: "ci/build"},
+			wantErr: errRulesetEntryExists,
+		},
+		{
+			name:    "rejects an existing check of another app",
+			ruleset: `{"rules": [{"type": "required_status_checks", "parameters": {"required_status_checks": [{"context": "ci/build", "integration_id": 1}], "strict_required_status_checks_policy": true}}]}`,
+			check:   &github.RuleStatusCheck{Context: "ci/build", IntegrationID: github.Ptr(int64(15368))},
+			wantErr: errRulesetEntryExists,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ruleset := decodeRuleset(t, test.ruleset)
+			err := addRequiredStatusCheck(ruleset, test.check)
+			if !errors.Is(err, test.wantErr) {
+				t.Fatalf("Expected error %v, got %v", test.wantErr, err)
+			}
+			if test.wantErr != nil {
+				return
+			}
+			data, err := json.Marshal(ruleset)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := rulesJSON(t, data); got != test.want {
+				t.Errorf("Expected rules %s, got %s", test.want, got)
+			}
+		})
+	}
+}
+
+func TestSetRequiredStatusCheck(t *testing.T) {
+	ruleset := decodeRuleset(t, `{"rules": [{"type": "required_status_checks", "parameters": {"required_status_checks": [{"context": "lint"}, {"context": "ci/build", "integration_id": 1}], "strict_required_status_checks_policy": true}}]}`)
+
+	if err := setRequiredStatusCheck(ruleset, &github.RuleStatusCheck{Context: "ci/build", IntegrationID: github.Ptr(int64(15368))}); err != nil {
+		t.Fatalf("setRequiredStatusCheck failed: %v", err)
+	}
+	data, _ := json.Marshal(ruleset)
+	want := `[{"parameters":{"required_status_checks":[{"context":"lint"},{"context":"ci/build","integration_id":15368}],"strict_required_status_checks_policy":true},"type":"required_status_checks"}]`
+	if got := rulesJSON(t, data); got != want {
+		t.Errorf("Expected rules %s, got %s", want, got)
+	}
+}
+
+func TestRemoveRequiredStatusCheck(t *testing.T) {
+	const ruleset = `{"rules": [{"type": "required_status_checks", "parameters": {"required_status_checks": [{"context": "lint"}, {"context": "ci/build", "integration_id": 1}], "strict_required_status_checks_policy": true}}]}`
+
+	tests := []struct {
+		name  string
+		check *github.RuleStatusCheck
+		want  string
+	}{
+		{
+			name:  "removes the check of an app",
+			check: &github.RuleStatusCheck{Context: "ci/build", IntegrationID: github.Ptr(int64(1))},
+			want:  `[{"parameters":{"required_status_checks":[{"context":"lint"}],"strict_required_status_checks_policy":true},"type":"required_status_checks"}]`,
+		},
+		{
+			name:  "removes the check whatever app reports it",
+			check: &github.RuleStatusCheck{Context: "ci/build"},
+			want:  `[{"parameters":{"required_status_checks":[{"context":"lint"}],"strict_required_status_checks_policy":true},"type":"required_status_checks"}]`,
+		},
+		{
+			name:  "ignores a missing check",
+			check: &github.RuleStatusCheck{Context: "test"},
+			want:  `[{"type":"required_status_checks","parameters":{"required_status_checks":[{"context":"lint"},{"context":"ci/build","integration_id":1}],"strict_required_status_checks_policy":true}}]`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ruleset := decodeRuleset(t, ruleset)
+			if err := removeRequiredStatusCheck(ruleset, test.check, true); err != nil {
+				t.Fatalf("removeRequiredStatusCheck failed: %v", err)
+			}
+			data, _ := json.Marshal(ruleset)
+			if got := rulesJSON(t, data); got != test.want {
+				t.Errorf("Expected rules %s, got %s", test.want, got)
+			}
+		})
+	}
+}
+
+func TestRemoveLastRequiredStatusCheck(t *testing.T) {
+	tests := []struct {
+		removeEmptyRule bool
+		want            string
+	}{
+		{removeEmptyRule: true, want: `[{"type":"deletion"}]`},
+		{removeEmptyRule: false, want: `[{"type":"deletion"},{"parameters":{"required_status_checks":[],"strict_required_status_checks_policy":true},"type":"required_status_checks"}]`},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("removeEmptyRule=%v", test.removeEmptyRule), func(t *testing.T) {
+			ruleset := decodeRuleset(t, `{"rules": [{"type": "deletion"}, {"type": "required_status_checks", "parameters": {"required_status_checks": [{"context": "ci/build"}], "strict_required_status_checks_policy": true}}]}`)
+			if err := removeRequiredStatusCheck(ruleset, &github.RuleStatusCheck{Context: "ci/build"}, test.removeEmptyRule); err != nil {
+				t.Fatalf("removeRequiredStatusCheck failed: %v", err)
+			}
+			data, _ := json.Marshal(ruleset)
+			if got := rulesJSON(t, data); got != test.want {
+				t.Errorf("Expected rules %s, got %s", test.want, got)
+			}
+		})
+	}
+}
+
+func TestRequiredStatusCheckCreateAndDelete(t *testing.T) {
+	tests := []struct {
+		name      string
+		ruleset   string
+		wantError bool
+		want      string
+	}{
+		{
+			name:    "removes the rule it added",
+			ruleset: `{"id": 123, "rules": [{"type": "deletion"}]}`,
+			want:    `[{"type":"deletion"}]`,
+		},
+		{
+			name:    "keeps a rule it did not add",
+			ruleset: `{"id": 123, "rules": [{"type": "required_status_checks", "parameters": {"required_status_checks": [], "strict_required_status_checks_policy": true}}]}`,
+			want:    `[{"parameters":{"required_status_checks":[],"strict_required_status_checks_policy":true},"type":"required_status_checks"}]`,
+		},
+		{
+			name:      "rejects an existing check",
+			ruleset:   `{"id": 123, "rules": [{"type": "required_status_checks", "parameters": {"required_status_checks": [{"context": "ci/build"}], "strict_required_status_checks_policy": true}}]}`,
+			wantError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client, puts := newRulesetServer(t, test.ruleset)
+			r := &rulesetRequiredStatusCheckResource{client: client}
+
+			ctx := context.Background()
+			plan := rulesetRequiredStatusCheckResourceModel{
+				rulesetTargetModel: rulesetTargetModel{Repository: types.StringValue("repo"), RulesetID: types.StringValue("123")},
+				Context:            types.StringValue("ci/build"),
+			}
+			previous, diags := r.create(ctx, &plan)
+			if diags.HasError() != test.wantError {
+				t.Fatalf("Expected error %v, got %v", test.wantError, diags)
+			}
+			if test.wantError {
+				if got := diags.Errors()[0].Summary(); got != "Ruleset entry already exists" {
+					t.Errorf("Unexpected error %q", got)
+				}
+				if len(*puts) != 0 {
+					t.Errorf("Expected no PUT, got %d", len(*puts))
+				}
+				return
+			}
+
+			if plan.ID.ValueString() != "owner/repo:123:ci/build" {
+				t.Errorf("Unexpected ID %s", plan.ID)
+			}
+			private := fakePrivateState{}
+			if diags := setPreviousRule(ctx, private, previous, nil); diags.HasError() {
+				t.Fatal(diags)
+			}
+			if diags := r.destroy(ctx, &plan, private); diags.HasError() {
+				t.Fatalf("destroy failed: %v", diags)
+			}
+			if len(*puts) != 2 {
+				t.Fatalf("Expected 2 PUTs, got %d", len(*puts))
+			}
+			if got := rulesJSON(t, (*puts)[1]); got != test.want {
+				t.Errorf("Expected rules %s, got %s", test.want, got)
+			}
+		})
+	}
+}
+
+func TestRequiredStatusCheckReadDrift(t *testing.T) {
+	const (
+		removed    = `[{"context": "lint"}]`
+		appChanged = `[{"context": "ci/build", "integration_id": 1}]`
+	)
+
+	tests := []struct {
+		name      string
+		driftMode string
+		checks    string
+		wantKept  bool
+		wantApp   int64
+		wantPut   bool
+	}{
+		{"restore removed check", driftModeRestore, removed, true, 15368, true},
+		{"report removed check", driftModeReport, removed, false, 0, false},
+		{"ignore removed check", driftModeIgnore, removed, true, 15368, false},
+		{"restore changed app", driftModeRestore, appChanged, true, 15368, true},
+		{"report changed app", driftModeReport, appChanged, true, 1, false},
+		{"ignore changed app", driftModeIgnore, appChanged, true, 15368, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client, puts := newRulesetServer(t, `{"id": 123, "rules": [{"type": "required_status_checks", "parameters": {"required_status_checks": `+test.checks+`, "strict_required_status_checks_policy": false}}]}`)
+			r := &rulesetRequiredStatusCheckResource{client: client, defaultDriftMode: test.driftMode}
+
+			state := rulesetRequiredStatusCheckResourceModel{
+				rulesetTargetModel: rulesetTargetModel{
+					Repository: types.StringValue("repo"),
+					RulesetID:  types.StringValue("123"),
+					ID:         types.StringValue("owner/repo:123:ci/build"),
+				},
+				Context:       types.StringValue("ci/build"),
+				IntegrationID: types.Int64Value(15368),
+			}
+
+			found, diags := r.refresh(context.Background(), &state)
+			if diags.HasError() {
+				t.Fatalf("refresh failed: %v", diags)
+			}
+			if found != test.wantKept {
+				t.Fatalf("Expected the resource to be kept %v, got %v", test.wantKept, found)
+			}
+			if found && state.IntegrationID.ValueInt64() != test.wantApp {
+				t.Errorf("Expected integration_id %d, got %s", test.wantApp, state.IntegrationID)
+			}
+			if (len(*puts) > 0) != test.wantPut {
+				t.Errorf("Expected PUT %v, got %d PUTs", test.wantPut, len(*puts))
+			}
+			if test.wantPut {
+				_, checks, err := requiredStatusChecks(decodeRuleset(t, string((*puts)[0])))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if i := statusCheckIndex(checks, "ci/build"); i < 0 || checks[i].GetIntegrationID() != 15368 {
+					t.Errorf("Expected the check to be restored, got %s", (*puts)[0])
+				}
+			}
+		})
+	}
+}
+
+func TestRequiredStatusCheckReadAfterImport(t *testing.T) {
+	client, _ := newRulesetServer(t, `{"id": 123, "rules": [{"type": "required_status_checks", "parameters": {"required_status_checks": [{"context": "ci/build", "integration_id": 15368}], "strict_required_status_checks_policy": false}}]}`)
+	r := &rulesetRequiredStatusCheckResource{client: client}
+
+	state := rulesetRequiredStatusCheckResourceModel{
+		rulesetTargetModel: rulesetTargetModel{Repository: types.StringValue("repo"), RulesetID: types.StringValue("123")},
+		Context:            types.StringValue("ci/build"),
+	}
+
+	found, diags := r.refresh(context.Background(), &state)
+	if diags.HasError() {
+		t.Fatalf("refresh failed: %v", diags)
+	}
+	if !found {
+		t.Fatal("Expected the resource to be kept")
+	}
+	if state.IntegrationID.ValueInt64() != 15368 {
+		t.Errorf("Expected the integration ID to be adopted, got %s", state.IntegrationID)
+	}
+	if state.ID.ValueString() != "owner/repo:123:ci/build" {
+		t.Errorf("Unexpected ID %s", state.ID)
+	}
+}
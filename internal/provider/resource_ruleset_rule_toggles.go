@@ -0,0 +1,410 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"slices"
+
+	"github.com/google/go-github/v74/github"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/knowledge-work/terraform-provider-kw-github/internal/githubclient"
+)
+
+func NewRulesetRuleTogglesResource() resource.Resource {
+	return &rulesetRuleTogglesResource{}
+}
+
+type rulesetRuleTogglesResource struct {
+	client           *githubclient.Client
+	defaultDriftMode string
+}
+
+type rulesetRuleTogglesResourceModel struct {
+	rulesetTargetModel
+	RequiredLinearHistory types.Bool   `tfsdk:"required_linear_history"`
+	RequiredSignatures    types.Bool   `tfsdk:"required_signatures"`
+	NonFastForward        types.Bool   `tfsdk:"non_fast_forward"`
+	Deletion              types.Bool   `tfsdk:"deletion"`
+	Creation              types.Bool   `tfsdk:"creation"`
+	DriftMode             types.String `tfsdk:"drift_mode"`
+	OnDestroy             types.String `tfsdk:"on_destroy"`
+}
+
+// privateKeyPreviousToggles is the private state key holding, by rule type,
+// whether the ruleset had each managed rule before the resource managed it.
+const privateKeyPreviousToggles = "previous_toggles"
+
+func (r *rulesetRuleTogglesResource) Metadata(
+	_ context.Context,
+	req resource.MetadataRequest,
+	resp *resource.MetadataResponse,
+) {
+	resp.TypeName = req.ProviderTypeName + "_ruleset_rule_toggles"
+}
+
+func (r *rulesetRuleTogglesResource) Schema(
+	_ context.Context,
+	_ resource.SchemaRequest,
+	resp *resource.SchemaResponse,
+) {
+	resp.Schema = schema.Schema{
+		Description: "Pins parameterless rules of a repository ruleset on or off. Rules that are not set are left untouched. " +
+			"Destroying the resource puts the managed rules back as they were before, unless on_destroy is 'leave_as_is'.",
+		Attributes: rulesetTargetAttributes(map[string]schema.Attribute{
+			"required_linear_history": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Whether the required_linear_history rule, which prevents merge commits, is enabled.",
+			},
+			"required_signatures": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Whether the required_signatures rule, which requires signed commits, is enabled.",
+			},
+			"non_fast_forward": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Whether the non_fast_forward rule, which blocks force pushes, is enabled.",
+			},
+			"deletion": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Whether the deletion rule, which restricts deleting matching refs, is enabled.",
+			},
+			"creation": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Whether the creation rule, which restricts creating matching refs, is enabled.",
+			},
+			"drift_mode": driftModeAttribute("a managed rule is toggled"),
+			"on_destroy": ruleOnDestroyAttribute("adds back or removes each managed rule, depending on whether the ruleset had it before the resource managed it"),
+		}),
+	}
+}
+
+func (r *rulesetRuleTogglesResource) Configure(
+	_ context.Context,
+	req resource.ConfigureRequest,
+	resp *resource.ConfigureResponse,
+) {
+	if req.ProviderData == nil {
+		return
+	}
+	data := req.ProviderData.(*providerData)
+	r.client = data.client
+	r.defaultDriftMode = data.driftMode
+}
+
+func (r *rulesetRuleTogglesResource) Create(
+	ctx context.Context,
+	req resource.CreateRequest,
+	resp *resource.CreateResponse,
+) {
+	var plan rulesetRuleTogglesResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	existed, diags := r.upsert(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(recordPreviousToggles(ctx, resp.Private, existed)...)
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+}
+
+func (r *rulesetRuleTogglesResource) Read(
+	ctx context.Context,
+	req resource.ReadRequest,
+	resp *resource.ReadResponse,
+) {
+	var state rulesetRuleTogglesResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	found, diags := r.refresh(ctx, &state, resp.Private)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if !found {
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// refresh handles rules toggled outside of Terraform according to the drift
+// mode. Right after import, all rules are adopted and recorded in private for
+// Delete. It returns false if the ruleset is gone.
+func (r *rulesetRuleTogglesResource) refresh(
+	ctx context.Context,
+	state *rulesetRuleTogglesResourceModel,
+	private privateState,
+) (bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	key, err := state.key(r.client.Owner)
+	if err != nil {
+		diags.AddError("Invalid ruleset", err.Error())
+		return false, diags
+	}
+
+	ruleset, diags := getRulesetTarget(ctx, r.client, key)
+	if ruleset == nil {
+		return false, diags
+	}
+
+	imported := state.ID.IsNull()
+	state.ID = types.StringValue(rulesetTargetID(key))
+
+	enabled := state.enabled()
+	drifted := false
+	for ruleType, on := range enabled {
+		if (ruleset.Rule(ruleType) != nil) != on {
+			drifted = true
+		}
+	}
+
+	useActual := imported
+	if !imported && drifted {
+		var driftDiags diag.Diagnostics
+		useActual, driftDiags = handleRuleDrift(
+			ctx, r.client, key, resolveDriftMode(state.DriftMode, r.defaultDriftMode),
+			func(ruleset *githubclient.Ruleset) error {
+				return toggleRules(ruleset, enabled)
+			},
+		)
+		diags.Append(driftDiags...)
+	}
+	if useActual {
+		for ruleType, toggle := range state.toggles() {
+			if imported || !toggle.IsNull() {
+				*toggle = types.BoolValue(ruleset.Rule(ruleType) != nil)
+			}
+		}
+	}
+	if imported {
+		diags.Append(recordPreviousToggles(ctx, private, existingRules(ruleset, state.enabled()))...)
+	}
+	return true, diags
+}
+
+func (r *rulesetRuleTogglesResource) Update(
+	ctx context.Context,
+	req resource.UpdateRequest,
+	resp *resource.UpdateResponse,
+) {
+	var plan rulesetRuleTogglesResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	existed, diags := r.upsert(ctx, &plan)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// Rules that became managed with this update are recorded as well.
+	resp.Diagnostics.Append(recordPreviousToggles(ctx, resp.Private, existed)...)
+	resp.Diagnostics.Append(resp.State.Set(ctx, plan)...)
+}
+
+func (r *rulesetRuleTogglesResource) Delete(
+	ctx context.Context,
+	req resource.DeleteRequest,
+	resp *resource.DeleteResponse,
+) {
+	var state rulesetRuleTogglesResourceModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.destroy(ctx, &state, req.Private)...)
+}
+
+// destroy adds back or removes each managed rule, depending on whether the
+// ruleset had it according to the record in private, unless on_destroy is
+// leave_as_is.
+func (r *rulesetRuleTogglesResource) destroy(
+	ctx context.Context,
+	state *rulesetRuleTogglesResourceModel,
+	private privateState,
+) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if state.OnDestroy.ValueString() == onDestroyLeaveAsIs {
+		return diags
+	}
+
+	key, err := state.key(r.client.Owner)
+	if err != nil {
+		diags.AddError("Invalid ruleset", err.Error())
+		return diags
+	}
+
+	previous, diags := getPreviousToggles(ctx, private)
+	if diags.HasError() {
+		return diags
+	}
+	if previous == nil {
+		diags.AddWarning(
+			"Previous rules unknown",
+			"Whether the ruleset had the rules before Terraform managed them was not recorded, so they are left as they are.",
+		)
+		return diags
+	}
+
+	restore := make(map[string]bool)
+	for ruleType := range state.enabled() {
+		if existed, ok := previous[ruleType]; ok {
+			restore[ruleType] = existed
+		}
+	}
+
+	_, err = r.client.ModifyRuleset(ctx, key, func(ruleset *githubclient.Ruleset) error {
+		return toggleRules(ruleset, restore)
+	})
+	if isRulesetGone(err) {
+		// Nothing left to restore.
+		return diags
+	}
+	if err != nil {
+		diags.AddError(rulesetWriteErrorDiagnostic("Error restoring ruleset", err))
+	}
+	return diags
+}
+
+func (r *rulesetRuleTogglesResource) ImportState(
+	ctx context.Context,
+	req resource.ImportStateRequest,
+	resp *resource.ImportStateResponse,
+) {
+	importRulesetTarget(ctx, req.ID, resp)
+}
+
+// upsert toggles the rules in plan and returns whether the ruleset had each
+// managed rule before, keyed by rule type.
+func (r *rulesetRuleTogglesResource) upsert(
+	ctx context.Context,
+	plan *rulesetRuleTogglesResourceModel,
+) (map[string]bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	key, err := plan.key(r.client.Owner)
+	if err != nil {
+		diags.AddError("Invalid ruleset", err.Error())
+		return nil, diags
+	}
+
+	enabled := plan.enabled()
+	var existed map[string]bool
+	_, err = r.client.ModifyRuleset(ctx, key, func(ruleset *githubclient.Ruleset) error {
+		existed = existingRules(ruleset, enabled)
+		return toggleRules(ruleset, enabled)
+	})
+	if err != nil {
+		diags.AddError(rulesetWriteErrorDiagnostic("Error updating ruleset", err))
+		return nil, diags
+	}
+
+	plan.ID = types.StringValue(rulesetTargetID(key))
+	return existed, diags
+}
+
+// toggles returns the toggle attributes of the model, keyed by rule type.
+func (m *rulesetRuleTogglesResourceModel) toggles() map[string]*types.Bool {
+	return map[string]*types.Bool{
+		string(github.RulesetRuleTypeRequiredLinearHistory): &m.RequiredLinearHistory,
+		string(github.RulesetRuleTypeRequiredSignatures):    &m.RequiredSignatures,
+		string(github.RulesetRuleTypeNonFastForward):        &m.NonFastForward,
+		string(github.RulesetRuleTypeDeletion):              &m.Deletion,
+		string(github.RulesetRuleTypeCreation):              &m.Creation,
+	}
+}
+
+// enabled returns whether each rule whose toggle is set should be enabled,
+// keyed by rule type.
+func (m *rulesetRuleTogglesResourceModel) enabled() map[string]bool {
+	enabled := make(map[string]bool)
+	for ruleType, toggle := range m.toggles() {
+		if !toggle.IsNull() {
+			enabled[ruleType] = toggle.ValueBool()
+		}
+	}
+	return enabled
+}
+
+// toggleRules adds the parameterless rules enabled maps to true and removes
+// the ones it maps to false. Rules that are already in the wanted state,
+// and all other rules, are kept as they are.
+func toggleRules(ruleset *githubclient.Ruleset, enabled map[string]bool) error {
+	for _, ruleType := range slices.Sorted(maps.Keys(enabled)) {
+		on := enabled[ruleType]
+		exists := ruleset.Rule(ruleType) != nil
+		switch {
+		case on && !exists:
+			rule, err := githubclient.NewRulesetRule(ruleType, nil)
+			if err != nil {
+				return err
+			}
+			ruleset.SetRule(rule)
+		case !on && exists:
+			ruleset.RemoveRule(ruleType)
+		}
+	}
+	return nil
+}
+
+// existingRules returns whether the ruleset has each rule type in enabled.
+func existingRules(ruleset *githubclient.Ruleset, enabled map[string]bool) map[string]bool {
+	existing := make(map[string]bool, len(enabled))
+	for ruleType := range enabled {
+		existing[ruleType] = ruleset.Rule(ruleType) != nil
+	}
+	return existing
+}
+
+// recordPreviousToggles records in private whether the ruleset had each rule
+// in existed, for destroy. Rules that are already recorded keep their record,
+// so that it always reflects the ruleset before the rule was first managed.
+func recordPreviousToggles(ctx context.Context, private privateState, existed map[string]bool) diag.Diagnostics {
+	previous, diags := getPreviousToggles(ctx, private)
+	if diags.HasError() {
+		return diags
+	}
+	if previous == nil {
+		previous = make(map[string]bool)
+	}
+	for ruleType, exists := range existed {
+		if _, ok := previous[ruleType]; !ok {
+			previous[ruleType] = exists
+		}
+	}
+
+	value, _ := json.Marshal(previous)
+	return private.SetKey(ctx, privateKeyPreviousToggles, value)
+}
+
+// getPreviousToggles returns the record of recordPreviousToggles, or nil if
+// there is none.
+func getPreviousToggles(ctx context.Context, private privateState) (map[string]bool, diag.Diagnostics) {
+	value, diags := private.GetKey(ctx, privateKeyPreviousToggles)
+	if diags.HasError() || len(value) == 0 {
+		return nil, diags
+	}
+
+	var previous map[string]bool
+	if err := json.Unmarshal(value, &previous); err != nil {
+		diags.AddError("Invalid private state", err.Error())
+		return nil, diags
+	}
+	return previous, diags
+}
@@ -0,0 +1,245 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+const togglesRuleset = `{"id": 123, "rules": [{"type": "deletion"}, {"type": "pull_request", "parameters": {"required_approving_review_count": 1}}, {"type": "non_fast_forward"}]}`
+
+func TestToggleRules(t *testing.T) {
+	ruleset := decodeRuleset(t, togglesRuleset)
+
+	err := toggleRules(ruleset, map[string]bool{
+		"required_signatures":     true,
+		"required_linear_history": true,
+		"non_fast_forward":        true,
+		"deletion":                false,
+	})
+	if err != nil {
+		t.Fatalf("toggleRules failed: %v", err)
+	}
+
+	data, _ := json.Marshal(ruleset)
+	want := `[{"type":"pull_request","parameters":{"required_approving_review_count":1}},{"type":"non_fast_forward"},{"type":"required_linear_history"},{"type":"required_signatures"}]`
+	if got := rulesJSON(t, data); got != want {
+		t.Errorf("Expected rules %s, got %s", want, got)
+	}
+}
+
+func TestRuleTogglesUpsert(t *testing.T) {
+	client, puts := newRulesetServer(t, togglesRuleset)
+	r := &rulesetRuleTogglesResource{client: client}
+
+	plan := rulesetRuleTogglesResourceModel{
+		rulesetTargetModel:    rulesetTargetModel{Repository: types.StringValue("repo"), RulesetID: types.StringValue("123")},
+		RequiredLinearHistory: types.BoolValue(true),
+		NonFastForward:        types.BoolValue(true),
+		Deletion:              types.BoolValue(false),
+	}
+	existed, diags := r.upsert(context.Background(), &plan)
+	if diags.HasError() {
+		t.Fatalf("upsert failed: %v", diags)
+	}
+	if want := map[string]bool{"required_linear_history": false, "non_fast_forward": true, "deletion": true}; !maps.Equal(existed, want) {
+		t.Errorf("Expected existing rules %v, got %v", want, existed)
+	}
+	if len(*puts) != 1 {
+		t.Fatalf("Expected 1 PUT, got %d", len(*puts))
+	}
+
+	want := `[{"type":"pull_request","parameters":{"required_approving_review_count":1}},{"type":"non_fast_forward"},{"type":"required_linear_history"}]`
+	if got := rulesJSON(t, (*puts)[0]); got != want {
+		t.Errorf("Expected rules %s, got %s", want, got)
+	}
+	if plan.ID.ValueString() != "owner/repo:123" {
+		t.Errorf("Unexpected ID %s", plan.ID)
+	}
+}
+
+func TestRuleTogglesReadDrift(t *testing.T) {
+	const restoredRules = `[{"type":"pull_request","parameters":{"required_approving_review_count":1}},{"type":"non_fast_forward"},{"type":"required_linear_history"}]`
+
+	for _, test := range []struct {
+		driftMode         string
+		wantLinearHistory bool
+		wantDeletion      bool
+		wantRules         string
+	}{
+		{driftMode: driftModeRestore, wantLinearHistory: true, wantDeletion: false, wantRules: restoredRules},
+		{driftMode: driftModeReport, wantLinearHistory: false, wantDeletion: true},
+		{driftMode: driftModeIgnore, wantLinearHistory: true, wantDeletion: false},
+	} {
+		t.Run(test.driftMode, func(t *testing.T) {
+			client, puts := newRulesetServer(t, togglesRuleset)
+			r := &rulesetRuleTogglesResource{client: client, defaultDriftMode: test.driftMode}
+
+			got := rulesetRuleTogglesResourceModel{
+				rulesetTargetModel: rulesetTargetModel{
+					Repository: types.StringValue("repo"),
+					RulesetID:  types.StringValue("123"),
+					ID:         types.StringValue("owner/repo:123"),
+				},
+				RequiredLinearHistory: types.BoolValue(true),
+				NonFastForward:        types.BoolValue(true),
+				Deletion:              types.BoolValue(false),
+			}
+
+			found, diags := r.refresh(context.Background(), &got, fakePrivateState{})
+			if diags.HasError() {
+				t.Fatalf("refresh failed: %v", diags)
+			}
+			if !found {
+				t.Fatal("Expected the resource to be kept")
+			}
+
+			for name, check := range map[string]struct {
+				got  types.Bool
+				want types.Bool
+			}{
+				"required_linear_history": {got.RequiredLinearHistory, types.BoolValue(test.wantLinearHistory)},
+				"required_signatures":     {got.RequiredSignatures, types.BoolNull()},
+				"non_fast_forward":        {got.NonFastForward, types.BoolValue(true)},
+				"deletion":                {got.Deletion, types.BoolValue(test.wantDeletion)},
+				"creation":                {got.Creation, types.BoolNull()},
+			} {
+				if !check.got.Equal(check.want) {
+					t.Errorf("Expected %s to be %s, got %s", name, check.want, check.got)
+				}
+			}
+
+			if test.wantRules == "" {
+				if len(*puts) != 0 {
+					t.Errorf("Expected Read not to write, got %d PUTs", len(*puts))
+				}
+				return
+			}
+			if len(*puts) != 1 {
+				t.Fatalf("Expected 1 PUT, got %d", len(*puts))
+			}
+			if got := rulesJSON(t, (*puts)[0]); got != test.wantRules {
+				t.Errorf("Expected rules %s, got %s", test.wantRules, got)
+			}
+		})
+	}
+}
+
+func TestRuleTogglesReadAfterImport(t *testing.T) {
+	client, _ := newRulesetServer(t, togglesRuleset)
+	r := &rulesetRuleTogglesResource{client: client}
+
+	got := rulesetRuleTogglesResourceModel{
+		rulesetTargetModel: rulesetTargetModel{Repository: types.StringValue("repo"), RulesetID: types.StringValue("123")},
+	}
+	private := fakePrivateState{}
+	if _, diags := r.refresh(context.Background(), &got, private); diags.HasError() {
+		t.Fatalf("refresh failed: %v", diags)
+	}
+	if !got.Deletion.ValueBool() || !got.NonFastForward.ValueBool() || got.Creation.ValueBool() || got.Creation.IsNull() {
+		t.Errorf("Expected every toggle to be adopted, got %+v", got)
+	}
+
+	previous, diags := getPreviousToggles(context.Background(), private)
+	if diags.HasError() {
+		t.Fatal(diags)
+	}
+	want := map[string]bool{"required_linear_history": false, "required_signatures": false, "non_fast_forward": true, "deletion": true, "creation": false}
+	if !maps.Equal(previous, want) {
+		t.Errorf("Expected the rules to be recorded as %v, got %v", want, previous)
+	}
+}
+
+func TestRecordPreviousToggles(t *testing.T) {
+	ctx := context.Background()
+	private := fakePrivateState{}
+
+	if diags := recordPreviousToggles(ctx, private, map[string]bool{"deletion": true}); diags.HasError() {
+		t.Fatal(diags)
+	}
+	if diags := recordPreviousToggles(ctx, private, map[string]bool{"deletion": false, "creation": false}); diags.HasError() {
+		t.Fatal(diags)
+	}
+
+	previous, diags := getPreviousToggles(ctx, private)
+	if diags.HasError() {
+		t.Fatal(diags)
+	}
+	if want := map[string]bool{"deletion": true, "creation": false}; !maps.Equal(previous, want) {
+		t.Errorf("Expected the first record of each rule to be kept, got %v", previous)
+	}
+}
+
+func TestRuleTogglesDestroy(t *testing.T) {
+	tests := []struct {
+		name      string
+		onDestroy types.String
+		want      string
+	}{
+		{
+			name:      "restores the previous rules",
+			onDestroy: types.StringNull(),
+			want:      `[{"type":"pull_request","parameters":{"required_approving_review_count":1}},{"type":"non_fast_forward"},{"type":"deletion"}]`,
+		},
+		{
+			name:      "leave_as_is",
+			onDestroy: types.StringValue(onDestroyLeaveAsIs),
+			want:      `[{"type":"pull_request","parameters":{"required_approving_review_count":1}},{"type":"non_fast_forward"},{"type":"required_linear_history"}]`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			client, puts := newRulesetServer(t, togglesRuleset)
+			r := &rulesetRuleTogglesResource{client: client}
+
+			plan := rulesetRuleTogglesResourceModel{
+				rulesetTargetModel:    rulesetTargetModel{Repository: types.StringValue("repo"), RulesetID: types.StringValue("123")},
+				RequiredLinearHistory: types.BoolValue(true),
+				NonFastForward:        types.BoolValue(true),
+				Deletion:              types.BoolValue(false),
+				OnDestroy:             test.onDestroy,
+			}
+			existed, diags := r.upsert(ctx, &plan)
+			if diags.HasError() {
+				t.Fatalf("upsert failed: %v", diags)
+			}
+			private := fakePrivateState{}
+			if diags := recordPreviousToggles(ctx, private, existed); diags.HasError() {
+				t.Fatal(diags)
+			}
+			if diags := r.destroy(ctx, &plan, private); diags.HasError() {
+				t.Fatalf("destroy failed: %v", diags)
+			}
+
+			if got := rulesJSON(t, (*puts)[len(*puts)-1]); got != test.want {
+				t.Errorf("Expected rules %s, got %s", test.want, got)
+			}
+		})
+	}
+}
+
+func TestRuleTogglesDestroyWithoutPrevious(t *testing.T) {
+	client, puts := newRulesetServer(t, togglesRuleset)
+	r := &rulesetRuleTogglesResource{client: client}
+
+	state := rulesetRuleTogglesResourceModel{
+		rulesetTargetModel: rulesetTargetModel{
+			Repository: types.StringValue("repo"),
+			RulesetID:  types.StringValue("123"),
+			ID:         types.StringValue("owner/repo:123"),
+		},
+		Deletion: types.BoolValue(false),
+	}
+	diags := r.destroy(context.Background(), &state, fakePrivateState{})
+	if diags.HasError() || diags.WarningsCount() != 1 {
+		t.Errorf("Expected a single warning, got %v", diags)
+	}
+	if len(*puts) != 0 {
+		t.Errorf("Expected no PUT, got %d", len(*puts))
+	}
+}
@@ -0,0 +1,137 @@
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/knowledge-work/terraform-provider-kw-github/internal/githubclient"
+)
+
+// privateKeyAppliedFingerprint is the private state key holding the ruleset
+// fingerprint right after the provider last wrote the ruleset.
+const privateKeyAppliedFingerprint = "applied_fingerprint"
+
+// privateState is implemented by the Private field of resource responses.
+type privateState interface {
+	GetKey(ctx context.Context, key string) ([]byte, diag.Diagnostics)
+	SetKey(ctx context.Context, key string, value []byte) diag.Diagnostics
+}
+
+// rulesetFingerprint hashes the rules of a ruleset that prepare keeps.
+// prepare receives a copy of each rule and returns false to leave it out. It
+// also removes the parameters managed by the resource itself, so only changes
+// made by someone else alter the fingerprint.
+func rulesetFingerprint(ruleset *githubclient.Ruleset, prepare func(rule *githubclient.RulesetRule) bool) (string, error) {
+	var rules []any
+	for _, rule := range ruleset.Rules() {
+		rule = rule.Clone()
+		if !prepare(rule) {
+			continue
+		}
+
+		data, err := json.Marshal(rule)
+		if err != nil {
+			return "", err
+		}
+		// Round-trip through any to get a canonical encoding with sorted keys.
+		var canonical any
+		if err := json.Unmarshal(data, &canonical); err != nil {
+			return "", err
+		}
+		rules = append(rules, canonical)
+	}
+
+	data, err := json.Marshal(rules)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// setAppliedFingerprint records fingerprint as the state of the ruleset right
+// after the provider wrote it.
+func setAppliedFingerprint(ctx context.Context, private privateState, fingerprint types.String) diag.Diagnostics {
+	if fingerprint.IsNull() || fingerprint.IsUnknown() {
+		return nil
+	}
+	value, _ := json.Marshal(fingerprint.ValueString())
+	return private.SetKey(ctx, privateKeyAppliedFingerprint, value)
+}
+
+// getAppliedFingerprint returns the fingerprint recorded by
+// setAppliedFingerprint, or an empty string if there is none.
+func getAppliedFingerprint(ctx context.Context, private privateState) (string, diag.Diagnostics) {
+	value, diags := private.GetKey(ctx, privateKeyAppliedFingerprint)
+	if diags.HasError() || len(value) == 0 {
+		return "", diags
+	}
+
+	var fingerprint string
+	if err := json.Unmarshal(value, &fingerprint); err != nil {
+		diags.AddError("Invalid private state", err.Error())
+	}
+	return fingerprint, diags
+}
+
+// planRulesetChanged plans an update by marking ruleset_fingerprint unknown
+// when the fingerprint refreshed by Read differs from the one recorded after
+// the provider's last write, i.e. someone else changed the ruleset since.
+// Like Read, it only acts on such changes in restore mode; 'report' and
+// 'ignore' leave the merge methods alone unless they were actually reset.
+func planRulesetChanged(
+	ctx context.Context,
+	req resource.ModifyPlanRequest,
+	resp *resource.ModifyPlanResponse,
+	defaultDriftMode string,
+) {
+	// Nothing to compare on create or destroy.
+	if req.State.Raw.IsNull() || req.Plan.Raw.IsNull() {
+		return
+	}
+
+	var driftMode, planned, refreshed types.String
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("drift_mode"), &driftMode)...)
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("ruleset_fingerprint"), &planned)...)
+	resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("ruleset_fingerprint"), &refreshed)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	// On other changes the value is already unknown because the update
+	// rewrites the ruleset.
+	if planned.IsUnknown() || driftMode.IsUnknown() {
+		return
+	}
+
+	changed, diags := rulesetChangedSinceWrite(ctx, req.Private, refreshed, resolveDriftMode(driftMode, defaultDriftMode))
+	resp.Diagnostics.Append(diags...)
+	if changed {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("ruleset_fingerprint"), types.StringUnknown())...)
+	}
+}
+
+// rulesetChangedSinceWrite reports whether an update should be planned
+// because the refreshed fingerprint differs from the applied one.
+func rulesetChangedSinceWrite(
+	ctx context.Context,
+	private privateState,
+	refreshed types.String,
+	driftMode string,
+) (bool, diag.Diagnostics) {
+	if driftMode != driftModeRestore || refreshed.IsNull() || refreshed.IsUnknown() {
+		return false, nil
+	}
+
+	applied, diags := getAppliedFingerprint(ctx, private)
+	if applied == "" || applied == refreshed.ValueString() {
+		return false, diags
+	}
+	return true, diags
+}
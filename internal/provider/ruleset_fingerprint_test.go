@@ -0,0 +1,147 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/knowledge-work/terraform-provider-kw-github/internal/githubclient"
+)
+
+func TestMergeMethodsFingerprint(t *testing.T) {
+	decode := func(t *testing.T, data string) *githubclient.Ruleset {
+		t.Helper()
+		ruleset := new(githubclient.Ruleset)
+		if err := json.Unmarshal([]byte(data), ruleset); err != nil {
+			t.Fatalf("Failed to decode ruleset: %v", err)
+		}
+		return ruleset
+	}
+
+	base := decode(t, `{
+		"id": 123,
+		"updated_at": "2024-01-01T00:00:00Z",
+		"rules": [
+			{"type": "deletion"},
+			{"type": "pull_request", "parameters": {"required_approving_review_count": 1, "allowed_merge_methods": ["squash"]}}
+		]
+	}`)
+	want, err := mergeMethodsFingerprint(base)
+	if err != nil {
+		t.Fatalf("Failed to compute fingerprint: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		ruleset string
+		changed bool
+	}{
+		{
+			name: "merge methods and metadata changed",
+			ruleset: `{
+				"id": 123,
+				"updated_at": "2024-02-01T00:00:00Z",
+				"rules": [
+					{"type": "deletion"},
+					{"type": "pull_request", "parameters": {"allowed_merge_methods": ["merge", "squash", "rebase"], "required_approving_review_count": 1}}
+				]
+			}`,
+			changed: false,
+		},
+		{
+			name: "overlay parameters changed",
+			ruleset: `{
+				"id": 123,
+				"rules": [
+					{"type": "deletion"},
+					{"type": "pull_request", "parameters": {"required_approving_review_count": 2, "automatic_copilot_code_review_enabled": true, "allowed_merge_methods": ["squash"]}}
+				]
+			}`,
+			changed: false,
+		},
+		{
+			name: "other pull request parameter added",
+			ruleset: `{
+				"id": 123,
+				"rules": [
+					{"type": "deletion"},
+					{"type": "pull_request", "parameters": {"required_approving_review_count": 1, "allowed_merge_methods": ["squash"], "future_param": true}}
+				]
+			}`,
+			changed: true,
+		},
+		{
+			name: "other rule removed",
+			ruleset: `{
+				"id": 123,
+				"rules": [
+					{"type": "pull_request", "parameters": {"required_approving_review_count": 1, "allowed_merge_methods": ["squash"]}}
+				]
+			}`,
+			changed: false,
+		},
+		{
+			name: "pull request rule removed",
+			ruleset: `{
+				"id": 123,
+				"rules": [
+					{"type": "deletion"}
+				]
+			}`,
+			changed: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := mergeMethodsFingerprint(decode(t, test.ruleset))
+			if err != nil {
+				t.Fatalf("Failed to compute fingerprint: %v", err)
+			}
+			if (got != want) != test.changed {
+				t.Errorf("Expected fingerprint changed to be %v, got %s and %s", test.changed, want, got)
+			}
+		})
+	}
+
+	// Computing the fingerprint must not modify the ruleset.
+	var methods []string
+	if _, err := base.Rule("pull_request").Parameter("allowed_merge_methods", &methods); err != nil || len(methods) != 1 {
+		t.Errorf("Expected allowed_merge_methods to be kept, got %v (%v)", methods, err)
+	}
+}
+
+func TestRulesetChangedSinceWrite(t *testing.T) {
+	tests := []struct {
+		name      string
+		applied   string
+		refreshed types.String
+		driftMode string
+		want      bool
+	}{
+		{"changed in restore mode", "a", types.StringValue("b"), driftModeRestore, true},
+		{"unchanged", "a", types.StringValue("a"), driftModeRestore, false},
+		{"changed in report mode", "a", types.StringValue("b"), driftModeReport, false},
+		{"changed in ignore mode", "a", types.StringValue("b"), driftModeIgnore, false},
+		{"nothing applied yet", "", types.StringValue("b"), driftModeRestore, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			private := fakePrivateState{}
+			if test.applied != "" {
+				setAppliedFingerprint(ctx, private, types.StringValue(test.applied))
+			}
+
+			got, diags := rulesetChangedSinceWrite(ctx, private, test.refreshed, test.driftMode)
+			if diags.HasError() {
+				t.Fatalf("rulesetChangedSinceWrite failed: %v", diags)
+			}
+			if got != test.want {
+				t.Errorf("Expected changed %v, got %v", test.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,357 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/knowledge-work/terraform-provider-kw-github/internal/githubclient"
+)
+
+// rulesetTargetModel holds the attributes that select the repository ruleset
+// a rule resource manages. It is embedded in the resource models.
+type rulesetTargetModel struct {
+	Owner      types.String `tfsdk:"owner"`
+	Repository types.String `tfsdk:"repository"`
+	RulesetID  types.String `tfsdk:"ruleset_id"`
+	ID         types.String `tfsdk:"id"`
+}
+
+// rulesetTargetAttributes adds the attributes of rulesetTargetModel to attrs.
+// Moving a resource to another ruleset replaces it, so the old ruleset is
+// cleaned up by Delete.
+func rulesetTargetAttributes(attrs map[string]schema.Attribute) map[string]schema.Attribute {
+	attrs["owner"] = schema.StringAttribute{
+		Optional:    true,
+		Description: "The owner of the repository. Defaults to the owner in repository, or the provider's owner.",
+		PlanModifiers: []planmodifier.String{
+			stringplanmodifier.RequiresReplace(),
+		},
+	}
+	attrs["repository"] = schema.StringAttribute{
+		Required:    true,
+		Description: "The name of the repository (e.g., 'repo-name'), or 'owner/repo-name' to manage a repository of another owner.",
+		PlanModifiers: []planmodifier.String{
+			stringplanmodifier.RequiresReplace(),
+		},
+	}
+	attrs["ruleset_id"] = schema.StringAttribute{
+		Required:    true,
+		Description: "The ID of the ruleset to manage.",
+		PlanModifiers: []planmodifier.String{
+			stringplanmodifier.RequiresReplace(),
+		},
+	}
+	attrs["id"] = schema.StringAttribute{
+		Computed: true,
+		PlanModifiers: []planmodifier.String{
+			stringplanmodifier.UseStateForUnknown(),
+		},
+	}
+	return attrs
+}
+
+// key returns the key of the selected ruleset.
+func (m rulesetTargetModel) key(defaultOwner string) (githubclient.RulesetKey, error) {
+	owner, repo, err := splitRepository(m.Owner, m.Repository, defaultOwner)
+	if err != nil {
+		return githubclient.RulesetKey{}, err
+	}
+	id, err := parseID(m.RulesetID.ValueString())
+	if err != nil {
+		return githubclient.RulesetKey{}, fmt.Errorf("invalid ruleset ID %q: %w", m.RulesetID.ValueString(), err)
+	}
+	return githubclient.RulesetKey{Owner: owner, Repo: repo, ID: id}, nil
+}
+
+// rulesetTargetID returns the resource ID for key followed by extra fields,
+// i.e. owner/repo:ruleset_id[:extra...].
+func rulesetTargetID(key githubclient.RulesetKey, extra ...string) string {
+	parts := append([]string{fmt.Sprintf("%s/%s:%d", key.Owner, key.Repo, key.ID)}, extra...)
+	return strings.Join(parts, ":")
+}
+
+// importRulesetTarget parses an import ID of the form
+// [owner/]repo:ruleset_id:<extra...>, sets the target attributes and returns
+// the extra fields. extra names the expected extra fields for the error
+// message. The last field may contain colons.
+func importRulesetTarget(
+	ctx context.Context,
+	importID string,
+	resp *resource.ImportStateResponse,
+	extra ...string,
+) ([]string, bool) {
+	parts := strings.SplitN(importID, ":", 2+len(extra))
+	valid := len(parts) == 2+len(extra)
+	for _, part := range parts {
+		valid = valid && part != ""
+	}
+	if valid {
+		_, _, err := splitRepository(types.StringNull(), types.StringValue(parts[0]), "")
+		_, idErr := parseID(parts[1])
+		valid = err == nil && idErr == nil
+	}
+	if !valid {
+		format := strings.Join(append([]string{"[owner/]repo", "ruleset_id"}, extra...), ":")
+		resp.Diagnostics.AddError(
+			"Unexpected Import Identifier",
+			fmt.Sprintf("Expected import identifier with format: %s. Got: %q", format, importID),
+		)
+		return nil, false
+	}
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("repository"), parts[0])...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("ruleset_id"), parts[1])...)
+	return parts[2:], !resp.Diagnostics.HasError()
+}
+
+// errRulesetEntryExists is returned when Create finds the entry it would add
+// to a ruleset already there. Adopting it would let Delete remove an entry
+// that Terraform did not add, so it has to be imported instead.
+var errRulesetEntryExists = errors.New("the entry already exists in the ruleset")
+
+// entryExistsDiagnostic returns the diagnostic summary and detail for
+// errRulesetEntryExists. entry describes the entry, importID is the ID to
+// import it with.
+func entryExistsDiagnostic(key githubclient.RulesetKey, entry, importID string) (string, string) {
+	return "Ruleset entry already exists",
+		fmt.Sprintf("Ruleset %s already has %s. To manage it with Terraform, import it with "+
+			"terraform import using the ID %q instead of creating it.", key, entry, importID)
+}
+
+// getRulesetTarget reads the ruleset for a rule resource's refresh. If the
+// ruleset or its repository is gone, nil is returned with a warning, and the
+// resource should be removed from state.
+func getRulesetTarget(
+	ctx context.Context,
+	client *githubclient.Client,
+	key githubclient.RulesetKey,
+) (*githubclient.Ruleset, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	ruleset, err := client.GetRuleset(ctx, key)
+	if isRulesetGone(err) {
+		diags.AddWarning(
+			"Ruleset no longer exists",
+			fmt.Sprintf("Ruleset %s was not found. It or the repository was deleted, archived or transferred, "+
+				"so the resource is removed from state.", key),
+		)
+		return nil, diags
+	}
+	if err != nil {
+		diags.AddError(githubErrorDiagnostic("Error reading a ruleset", err))
+		return nil, diags
+	}
+	return ruleset, diags
+}
+
+// handleRuleDrift applies driftMode after Read found that the settings on
+// GitHub differ from state. In restore mode, apply writes the settings in
+// state back. It reports whether the settings read from GitHub should be
+// stored in state, which is the case in report mode and when restoring fails.
+func handleRuleDrift(
+	ctx context.Context,
+	client *githubclient.Client,
+	key githubclient.RulesetKey,
+	driftMode string,
+	apply func(ruleset *githubclient.Ruleset) error,
+) (bool, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	switch driftMode {
+	case driftModeIgnore:
+		return false, diags
+	case driftModeReport:
+		return true, diags
+	}
+
+	if _, err := client.ModifyRuleset(ctx, key, apply); err != nil {
+		diags.AddWarning(
+			"Ruleset settings were reset",
+			fmt.Sprintf("The settings of ruleset %s were changed outside of Terraform, attempted to restore but failed: %v", key, err),
+		)
+		return true, diags
+	}
+	return false, diags
+}
+
+// setRuleParameters sets params on the ruleset's rule of ruleType, adding the
+// rule if the ruleset has none. Parameters this provider does not know are
+// kept.
+func setRuleParameters(ruleset *githubclient.Ruleset, ruleType string, params map[string]any) error {
+	rule := ruleset.Rule(ruleType)
+	if rule == nil {
+		rule, err := githubclient.NewRulesetRule(ruleType, params)
+		if err != nil {
+			return err
+		}
+		ruleset.SetRule(rule)
+		return nil
+	}
+
+	for name, value := range params {
+		if err := rule.SetParameter(name, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// driftModeAttribute returns the drift_mode attribute of a resource. changed
+// completes "What to do when ... outside of Terraform", e.g. "the rule was
+// changed".
+func driftModeAttribute(changed string) schema.StringAttribute {
+	return schema.StringAttribute{
+		Optional: true,
+		Description: "What to do when " + changed + " outside of Terraform. " +
+			"'restore' writes the configured settings back during refresh, " +
+			"'report' shows the drift as a plan diff and fixes it on apply, 'ignore' does nothing. " +
+			"Defaults to the provider's drift_mode.",
+		Validators: []validator.String{
+			stringvalidator.OneOf(driftModes...),
+		},
+	}
+}
+
+// ruleOnDestroyModes are the on_destroy values of resources that manage
+// parameters of a single rule.
+var ruleOnDestroyModes = []string{onDestroyRestorePrevious, onDestroyLeaveAsIs}
+
+// ruleOnDestroyAttribute returns the on_destroy attribute of a resource that
+// manages parameters of a single rule. restores completes "'restore_previous'
+// (default) ...".
+func ruleOnDestroyAttribute(restores string) schema.StringAttribute {
+	return schema.StringAttribute{
+		Optional: true,
+		Description: "What to do when the resource is destroyed. 'restore_previous' (default) " + restores +
+			", 'leave_as_is' leaves the ruleset as it is.",
+		Validators: []validator.String{
+			stringvalidator.OneOf(ruleOnDestroyModes...),
+		},
+	}
+}
+
+// privateKeyPreviousRule is the private state key holding the previousRule
+// recorded when the resource took over a rule.
+const privateKeyPreviousRule = "previous_rule"
+
+// previousRule is a rule as it was before a resource took it over.
+type previousRule struct {
+	// Exists reports whether the ruleset had the rule.
+	Exists bool `json:"exists"`
+	// Parameters holds the recorded parameters that were set, by name.
+	Parameters map[string]json.RawMessage `json:"parameters,omitempty"`
+}
+
+// setPreviousRule records the parameters names of rule, which is nil if the
+// ruleset has no such rule, for restorePreviousRule.
+func setPreviousRule(
+	ctx context.Context,
+	private privateState,
+	rule *githubclient.RulesetRule,
+	names []string,
+) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	previous := previousRule{Exists: rule != nil}
+	if rule != nil {
+		previous.Parameters = make(map[string]json.RawMessage)
+		for _, name := range names {
+			var value json.RawMessage
+			present, err := rule.Parameter(name, &value)
+			if err != nil {
+				diags.AddError("Error reading a ruleset", err.Error())
+				return diags
+			}
+			if present {
+				previous.Parameters[name] = value
+			}
+		}
+	}
+
+	value, _ := json.Marshal(previous)
+	return private.SetKey(ctx, privateKeyPreviousRule, value)
+}
+
+// getPreviousRule returns the rule recorded by setPreviousRule, or nil if
+// there is none.
+func getPreviousRule(ctx context.Context, private privateState) (*previousRule, diag.Diagnostics) {
+	value, diags := private.GetKey(ctx, privateKeyPreviousRule)
+	if diags.HasError() || len(value) == 0 {
+		return nil, diags
+	}
+
+	var previous previousRule
+	if err := json.Unmarshal(value, &previous); err != nil {
+		diags.AddError("Invalid private state", err.Error())
+		return nil, diags
+	}
+	return &previous, diags
+}
+
+// restorePreviousRule resets the parameters names of the ruleset's rule of
+// ruleType to previous, or removes the rule if it did not exist before. Other
+// parameters are kept. A rule that was removed in the meantime stays removed.
+func restorePreviousRule(ruleset *githubclient.Ruleset, ruleType string, previous *previousRule, names []string) error {
+	rule := ruleset.Rule(ruleType)
+	if rule == nil {
+		return nil
+	}
+	if !previous.Exists {
+		ruleset.RemoveRule(ruleType)
+		return nil
+	}
+
+	for _, name := range names {
+		value, ok := previous.Parameters[name]
+		if !ok {
+			rule.DeleteParameter(name)
+			continue
+		}
+		if err := rule.SetParameter(name, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// destroyRule restores the parameters names of the rule of ruleType in the
+// ruleset identified by key to previous when the resource is destroyed,
+// unless onDestroy is leave_as_is.
+func destroyRule(
+	ctx context.Context,
+	client *githubclient.Client,
+	key githubclient.RulesetKey,
+	ruleType string,
+	names []string,
+	onDestroy types.String,
+	previous *previousRule,
+) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if onDestroy.ValueString() == onDestroyLeaveAsIs {
+		return diags
+	}
+
+	_, err := client.ModifyRuleset(ctx, key, func(ruleset *githubclient.Ruleset) error {
+		return restorePreviousRule(ruleset, ruleType, previous, names)
+	})
+	if isRulesetGone(err) {
+		// Nothing left to restore.
+		return diags
+	}
+	if err != nil {
+		diags.AddError(rulesetWriteErrorDiagnostic("Error restoring ruleset", err))
+	}
+	return diags
+}
@@ -0,0 +1,76 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-github/v74/github"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/knowledge-work/terraform-provider-kw-github/internal/githubclient"
+)
+
+// Helpers shared by the tests of the resources that manage a part of a
+// repository ruleset.
+
+// newRulesetServer serves ruleset 123 of owner/repo, initially as the given
+// JSON, and records the body of every PUT. A PUT replaces the served ruleset.
+func newRulesetServer(t *testing.T, ruleset string) (*githubclient.Client, *[]json.RawMessage) {
+	t.Helper()
+
+	var puts []json.RawMessage
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/owner/repo/rulesets/123", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "PUT" {
+			var body json.RawMessage
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Errorf("failed to decode PUT body: %v", err)
+			}
+			puts = append(puts, body)
+			ruleset = string(body)
+		}
+		fmt.Fprint(w, ruleset)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	client := github.NewClient(nil)
+	client.BaseURL, _ = client.BaseURL.Parse(server.URL + "/")
+	return &githubclient.Client{Client: client, Owner: "owner"}, &puts
+}
+
+func decodeRuleset(t *testing.T, data string) *githubclient.Ruleset {
+	t.Helper()
+	var ruleset githubclient.Ruleset
+	if err := json.Unmarshal([]byte(data), &ruleset); err != nil {
+		t.Fatal(err)
+	}
+	return &ruleset
+}
+
+// rulesJSON returns the rules of the encoded ruleset data.
+func rulesJSON(t *testing.T, data []byte) string {
+	t.Helper()
+	var ruleset struct {
+		Rules json.RawMessage `json:"rules"`
+	}
+	if err := json.Unmarshal(data, &ruleset); err != nil {
+		t.Fatal(err)
+	}
+	return string(ruleset.Rules)
+}
+
+// fakePrivateState is an in-memory privateState.
+type fakePrivateState map[string][]byte
+
+func (p fakePrivateState) GetKey(_ context.Context, key string) ([]byte, diag.Diagnostics) {
+	return p[key], nil
+}
+
+func (p fakePrivateState) SetKey(_ context.Context, key string, value []byte) diag.Diagnostics {
+	p[key] = value
+	return nil
+}